@@ -0,0 +1,101 @@
+package gocdp
+
+// AutoCalibration configures the removal of clusters of results sharing the same key, such as wildcard or soft 404 responses
+type AutoCalibration struct {
+	// Key is what the results are clustered by. Defaults to KeyStatusLength
+	Key KeyFunc
+	// MinCount is the number of results a cluster needs to be removed. 0 disables it
+	MinCount int
+	// MinShare is the share of the results remaining after the other filters a cluster needs to be removed
+	// e.g. 0.25 for 25%. 0 disables it. The results removed by the filters count in neither, whatever the order of the options
+	MinShare float64
+	// Samples is the number of results kept from each removed cluster
+	Samples int
+}
+
+// TrimCluster is a cluster of results removed by the auto calibration
+type TrimCluster struct {
+	Key     string
+	Total   int
+	Removed int
+	// Sample is the URL of the first result kept from the cluster, or of the first result when none are kept
+	Sample string
+}
+
+// WithAutoCalibration removes the clusters of results sharing the same key above the count or share in the AutoCalibration,
+// keeping the configured number of samples from each. The removed clusters are in the TrimReport.
+// When neither MinCount nor MinShare is set, MinCount defaults to 50
+func WithAutoCalibration(auto AutoCalibration) TrimOption {
+	return func(o *TrimOptions) {
+		if auto.Key == nil {
+			auto.Key = KeyStatusLength
+		}
+
+		if auto.MinCount <= 0 && auto.MinShare <= 0 {
+			auto.MinCount = 50
+		}
+
+		o.auto = &auto
+	}
+}
+
+func (a *AutoCalibration) isCluster(count int, total int) bool {
+	if count <= a.Samples {
+		return false
+	}
+
+	if a.MinCount > 0 && count >= a.MinCount {
+		return true
+	}
+	return a.MinShare > 0 && float64(count)/float64(total) >= a.MinShare
+}
+
+// apply marks the results in the clusters, except for the samples, as removed. Results already removed are ignored
+// The kept samples of each cluster are picked by the sampler
+func (a *AutoCalibration) apply(results CDResults, removed []string, report *TrimReport, sampler Sampler) {
+	var keys []string
+	groups := make(map[string][]int)
+	total := 0
+	for i, result := range results {
+		if removed[i] != "" {
+			continue
+		}
+
+		key := a.Key(result)
+		if len(groups[key]) == 0 {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+		total += 1
+	}
+
+	for _, key := range keys {
+		indexes := groups[key]
+		if !a.isCluster(len(indexes), total) {
+			continue
+		}
+
+		cluster := TrimCluster{
+			Key:    key,
+			Total:  len(indexes),
+			Sample: results[indexes[0]].Url,
+		}
+
+		keep := sampleGroup(sampler, results, indexes, a.Samples)
+		sampled := false
+		for _, i := range indexes {
+			if keep[i] {
+				if !sampled {
+					cluster.Sample = results[i].Url
+					sampled = true
+				}
+				continue
+			}
+
+			removed[i] = "auto"
+			cluster.Removed += 1
+		}
+
+		report.Clusters = append(report.Clusters, cluster)
+	}
+}
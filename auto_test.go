@@ -0,0 +1,144 @@
+package gocdp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wildcardResults returns count wildcard results with the same status and length followed by the real results
+func wildcardResults(count int) CDResults {
+	var results CDResults
+	for i := 0; i < count; i++ {
+		results = append(results, CDResult{Url: fmt.Sprintf("http://t/w%d", i), Status: 200, ContentLength: 42})
+	}
+
+	return append(results,
+		CDResult{Url: "http://t/admin", Status: 403, ContentLength: 199},
+		CDResult{Url: "http://t/index.html", Status: 200, ContentLength: 612},
+	)
+}
+
+// autoTrim trims the results with the auto calibration and returns the report
+func autoTrim(t *testing.T, results CDResults, auto AutoCalibration, opts ...TrimOption) *TrimReport {
+	t.Helper()
+
+	input, err := RenderFfufJSON(results, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report *TrimReport
+	opts = append(opts, WithAutoCalibration(auto), WithReport(func(r *TrimReport) {
+		report = r
+	}))
+
+	if _, err := SmartTrim(strings.NewReader(input), opts); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestAutoCalibration(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  CDResults
+		auto     AutoCalibration
+		opts     []TrimOption
+		expected []TrimCluster
+	}{
+		{
+			name:     "count",
+			results:  wildcardResults(20),
+			auto:     AutoCalibration{MinCount: 20, Samples: 2},
+			expected: []TrimCluster{{Key: "200|42", Total: 20, Removed: 18, Sample: "http://t/w0"}},
+		},
+		{
+			name:     "below count",
+			results:  wildcardResults(19),
+			auto:     AutoCalibration{MinCount: 20},
+			expected: nil,
+		},
+		{
+			name:     "default count",
+			results:  wildcardResults(49),
+			auto:     AutoCalibration{},
+			expected: nil,
+		},
+		{
+			name:     "default count reached",
+			results:  wildcardResults(50),
+			auto:     AutoCalibration{},
+			expected: []TrimCluster{{Key: "200|42", Total: 50, Removed: 50, Sample: "http://t/w0"}},
+		},
+		{
+			name:     "share",
+			results:  wildcardResults(6),
+			auto:     AutoCalibration{MinShare: 0.75, Samples: 1},
+			expected: []TrimCluster{{Key: "200|42", Total: 6, Removed: 5, Sample: "http://t/w0"}},
+		},
+		{
+			name:     "share of the results left by the filters",
+			results:  wildcardResults(4),
+			auto:     AutoCalibration{MinShare: 0.9},
+			opts:     []TrimOption{WithFilterStatus(403), WithFilterLength(612)},
+			expected: []TrimCluster{{Key: "200|42", Total: 4, Removed: 4, Sample: "http://t/w0"}},
+		},
+		{
+			name:     "samples cover the cluster",
+			results:  wildcardResults(5),
+			auto:     AutoCalibration{MinCount: 2, Samples: 5},
+			expected: nil,
+		},
+		{
+			name:     "key",
+			results:  wildcardResults(3),
+			auto:     AutoCalibration{Key: KeyStatus, MinCount: 4},
+			expected: []TrimCluster{{Key: "200", Total: 4, Removed: 4, Sample: "http://t/w0"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := autoTrim(t, test.results, test.auto, test.opts...)
+			if !reflect.DeepEqual(report.Clusters, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, report.Clusters)
+			}
+
+			removed := 0
+			for _, cluster := range test.expected {
+				removed += cluster.Removed
+			}
+			if report.Removed["auto"] != removed {
+				t.Errorf("expected %d results removed by auto, got %d", removed, report.Removed["auto"])
+			}
+		})
+	}
+}
+
+func TestAutoCalibrationShareAfterFilters(t *testing.T) {
+	// 4 of the 6 results are in the cluster, which is 100% of the results left by the filters
+	auto := WithAutoCalibration(AutoCalibration{MinShare: 0.9})
+	filters := []TrimOption{WithFilterStatus(403), WithFilterLength(612)}
+
+	for _, opts := range [][]TrimOption{append([]TrimOption{auto}, filters...), append(filters, auto)} {
+		if urls := trimURLs(t, wildcardResults(4), opts...); len(urls) != 0 {
+			t.Errorf("expected every result to be removed, got %v", urls)
+		}
+	}
+
+	// Without the filters the cluster is only 4 of the 6 results
+	if urls := trimURLs(t, wildcardResults(4), auto); len(urls) != 6 {
+		t.Errorf("expected every result to be kept, got %v", urls)
+	}
+}
+
+func TestAutoCalibrationKeepsOtherResults(t *testing.T) {
+	urls := trimURLs(t, wildcardResults(10), WithAutoCalibration(AutoCalibration{MinCount: 10, Samples: 1}))
+
+	expected := []string{"http://t/w0", "http://t/admin", "http://t/index.html"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected %v, got %v", expected, urls)
+	}
+}
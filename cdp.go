@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 )
 
 var c *CDP
@@ -14,7 +15,12 @@ var defaultParsers = []Parser{
 	FfufParser{},
 	GobusterParser{},
 	DirbParser{},
+	HttpxParser{},
 	FeroxbusterParser{},
+	NmapParser{},
+	NiktoParser{},
+	ArjunParser{},
+	X8Parser{},
 	DirSearchParser{},
 }
 
@@ -34,9 +40,18 @@ func DefaultParsers(parsers ...Parser) Option {
 	}
 }
 
+// AdditionalParsers adds parsers to the end of the default parsers. This is used for parsers which
+// are not safe to detect by default, such as the URLListParser
+func AdditionalParsers(parsers ...Parser) Option {
+	return func(c *CDP) {
+		c.additionalParsers = append(c.additionalParsers, parsers...)
+	}
+}
+
 type CDP struct {
-	defaultParsers  []Parser
-	failNoParserErr bool
+	defaultParsers    []Parser
+	additionalParsers []Parser
+	failNoParserErr   bool
 }
 
 func New(options ...Option) *CDP {
@@ -49,6 +64,12 @@ func New(options ...Option) *CDP {
 		cdp.defaultParsers = defaultParsers
 	}
 
+	if len(cdp.additionalParsers) != 0 {
+		var parsers []Parser
+		parsers = append(parsers, cdp.defaultParsers...)
+		cdp.defaultParsers = append(parsers, cdp.additionalParsers...)
+	}
+
 	return cdp
 }
 
@@ -72,13 +93,57 @@ func (cdp *CDP) SmartParseFiles(files []string, parsers ...Parser) (CDResults, e
 }
 
 func (cdp *CDP) SmartParseFile(file string, parsers ...Parser) (CDResults, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return cdp.SmartParseDir(file)
+	}
+
 	f, err := os.Open(file)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	return cdp.SmartParse(f, parsers...)
+	results, err := cdp.SmartParse(f, parsers...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].file = file
+	}
+	return results, nil
+}
+
+// SmartParseDir parses an output directory. Currently only meg output directories, which contain an index file, are supported
+func (cdp *CDP) SmartParseDir(dir string) (CDResults, error) {
+	index, err := os.ReadFile(filepath.Join(dir, "index"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errNoParser
+		}
+		return nil, err
+	}
+
+	parser := MegParser{Dir: dir}
+	input := string(index)
+	if !parser.CanParse(input) {
+		return nil, errNoParser
+	}
+
+	results, err := parser.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].file = dir
+	}
+	return results, nil
 }
 
 func (cdp *CDP) SmartParse(reader io.Reader, parsers ...Parser) (CDResults, error) {
@@ -119,13 +184,34 @@ func SmartParseFile(file string, parsers ...Parser) (CDResults, error) {
 	return c.SmartParseFile(file, parsers...)
 }
 
+func SmartParseDir(dir string) (CDResults, error) {
+	return c.SmartParseDir(dir)
+}
+
 func SmartParse(reader io.Reader, parsers ...Parser) (CDResults, error) {
 	return c.SmartParse(reader, parsers...)
 }
 
 func (cdp *CDP) SmartTrimFiles(files []string, opts []TrimOption, parsers ...Parser) error {
+	opts = append(opts[:len(opts):len(opts)], withTrimFiles(files))
+
+	// Fail on invalid options before any file is trimmed
+	options := newTrimOptions(opts)
+	if options.err != nil {
+		return options.err
+	}
+
+	err := options.checkOutputFiles(files)
+	if err != nil {
+		return err
+	}
+
+	if options.allOrNothing {
+		return cdp.smartTrimFilesAllOrNothing(files, opts, parsers...)
+	}
+
 	for _, file := range files {
-		err := cdp.SmartTrimFile(file, opts, parsers...)
+		err = cdp.SmartTrimFile(file, opts, parsers...)
 		if err != nil {
 			if err == errNoParser {
 				if cdp.failNoParserErr {
@@ -139,31 +225,90 @@ func (cdp *CDP) SmartTrimFiles(files []string, opts []TrimOption, parsers ...Par
 	return nil
 }
 
+// SmartTrimFile trims the file and atomically writes the output, keeping the original mode and ownership.
+// By default the file is overwritten, see WithOutputDir, WithOutputSuffix and WithBackup
 func (cdp *CDP) SmartTrimFile(file string, opts []TrimOption, parsers ...Parser) error {
-	f, err := os.Open(file)
+	options := newTrimOptions(opts)
+
+	report, info, err := cdp.smartTrimFile(file, options, parsers...)
 	if err != nil {
 		return err
 	}
 
-	output, err := cdp.SmartTrim(f, opts, parsers...)
+	if options.report != nil {
+		options.report(report)
+	}
+
+	if options.dryRun {
+		return nil
+	}
+
+	if options.backupSuffix != "" && report.OutputFile == file {
+		_, err = backupFile(file, file+options.backupSuffix)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeFileAtomic(report.OutputFile, []byte(report.Output), info)
 	if err != nil {
 		return err
 	}
+	return options.cleanupResponses(report)
+}
 
-	err = f.Close()
+// smartTrimFile trims the file without writing anything, returning the report and the info of the file
+func (cdp *CDP) smartTrimFile(file string, options *TrimOptions, parsers ...Parser) (*TrimReport, os.FileInfo, error) {
+	f, err := os.Open(file)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return os.WriteFile(file, []byte(output), 0644)
+	options.file = file
+	defer func() {
+		options.file = ""
+	}()
+
+	report, err := cdp.smartTrim(f, options, parsers...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report.File = file
+	report.OutputFile = options.outputFile(file)
+	return report, info, nil
 }
 
 func (cdp *CDP) SmartTrim(reader io.Reader, opts []TrimOption, parsers ...Parser) (string, error) {
-	bytes, err := io.ReadAll(reader)
+	options := newTrimOptions(opts)
+
+	report, err := cdp.smartTrim(reader, options, parsers...)
 	if err != nil {
 		return "", err
 	}
 
+	if options.report != nil {
+		options.report(report)
+	}
+	return report.Output, nil
+}
+
+func (cdp *CDP) smartTrim(reader io.Reader, options *TrimOptions, parsers ...Parser) (*TrimReport, error) {
+	if options.err != nil {
+		return nil, options.err
+	}
+
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(parsers) == 0 {
 		parsers = cdp.defaultParsers
 	}
@@ -178,59 +323,70 @@ func (cdp *CDP) SmartTrim(reader io.Reader, opts []TrimOption, parsers ...Parser
 	}
 
 	if parser == nil {
-		return "", errNoParser
+		return nil, errNoParser
 	}
 
 	results, err := parser.Parse(input)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	options := &TrimOptions{
-		filters:  make([]func(CDResult) bool, 0),
-		operator: OrOperator,
+	for i := range results {
+		results[i].file = options.file
 	}
 
-	for _, o := range opts {
-		o(options)
+	report := &TrimReport{
+		Total:   len(results),
+		Removed: make(map[string]int),
+		Input:   input,
 	}
 
-	statusCounts := make(map[int]int)
-
-	var filtered []interface{}
-	for _, result := range results {
-		isFiltered := false
-		if options.operator == AndOperator {
-			isFiltered = true
+	removed := make([]string, len(results))
+	for i, result := range results {
+		removed[i], err = options.removedBy(result)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		for _, filter := range options.filters {
-			if filter(result) {
-				if options.operator == OrOperator {
-					isFiltered = true
-					break
-				}
-			} else if options.operator == AndOperator {
-				isFiltered = false
-				break
+	if options.auto != nil {
+		options.auto.apply(results, removed, report, options.sampler)
+	}
+	options.capResults(results, removed)
+
+	var kept CDResults
+	var filtered []Record
+	for i, result := range results {
+		removedBy := removed[i]
+		if removedBy != "" {
+			report.remove(removedBy)
+			if result.ResponseFile != "" {
+				report.ResponseFiles = append(report.ResponseFiles, responseFile(options.file, result))
 			}
+			continue
 		}
 
-		if !isFiltered && options.maxResults > 0 {
-			isFiltered = statusCounts[result.Status] >= options.maxResults
-		}
-
-		if !isFiltered {
-			statusCounts[result.Status] += 1
-			filtered = append(filtered, result.source)
-		}
+		kept = append(kept, result)
+		filtered = append(filtered, result.source)
 	}
+	report.Kept = len(filtered)
+	report.ResponseFiles = withoutKeptResponses(report.ResponseFiles, kept, options.file)
 
 	output, err := parser.Transform(input, filtered)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return output, nil
+	report.Output = output
+
+	if options.verify {
+		err = verifyTransform(parser, output, kept)
+		if err != nil {
+			return nil, err
+		}
+		report.Verified = true
+	}
+
+	return report, nil
 }
 
 func SmartTrimFiles(files []string, opts []TrimOption, parsers ...Parser) error {
@@ -0,0 +1,130 @@
+package gocdp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFfufFile writes an ffuf JSON file with a 200 result for each URL
+func writeFfufFile(t *testing.T, file string, urls ...string) {
+	t.Helper()
+
+	var results CDResults
+	for _, u := range urls {
+		results = append(results, CDResult{Url: u, Status: 200, ContentLength: 10})
+	}
+
+	output, err := RenderFfufJSON(results, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(file, []byte(output), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fileURLs returns the URLs of the results parsed from the file
+func fileURLs(t *testing.T, file string) []string {
+	t.Helper()
+
+	results, err := SmartParseFile(file)
+	if err != nil {
+		t.Fatalf("%s: %v", file, err)
+	}
+
+	urls := []string{}
+	for _, result := range results {
+		urls = append(urls, result.Url)
+	}
+	return urls
+}
+
+func TestSmartTrimFileBackupKeepsFirstBackup(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b", "http://t/c")
+
+	err := SmartTrimFile(file, []TrimOption{WithFilterURL("/b$"), WithBackup("")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SmartTrimFile(file, []TrimOption{WithFilterURL("/c$"), WithBackup("")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if urls := fileURLs(t, file); !reflect.DeepEqual(urls, []string{"http://t/a"}) {
+		t.Errorf("trimmed file has %v", urls)
+	}
+
+	expected := []string{"http://t/a", "http://t/b", "http://t/c"}
+	if urls := fileURLs(t, file+".orig"); !reflect.DeepEqual(urls, expected) {
+		t.Errorf("backup has %v, expected the original %v", urls, expected)
+	}
+}
+
+func TestSmartTrimFileOutputDirKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b")
+
+	out := filepath.Join(dir, "out")
+	err := SmartTrimFile(file, []TrimOption{WithFilterURL("/b$"), WithOutputDir(out)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if urls := fileURLs(t, file); len(urls) != 2 {
+		t.Errorf("original file has %v", urls)
+	}
+	if urls := fileURLs(t, filepath.Join(out, "f1.json")); !reflect.DeepEqual(urls, []string{"http://t/a"}) {
+		t.Errorf("trimmed file has %v", urls)
+	}
+}
+
+func TestSmartTrimFileDryRunReport(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b", "http://t/c", "http://t/static/d")
+
+	before, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report *TrimReport
+	err = SmartTrimFile(file, []TrimOption{
+		WithFilterURL("/b$", "/c$"),
+		WithFilterURLMatch(MatchLiteral, "/static/"),
+		WithDryRun(),
+		WithReport(func(r *TrimReport) {
+			report = r
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Error("the dry run changed the file")
+	}
+
+	if report.File != file || report.OutputFile != file || report.Total != 4 || report.Kept != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if expected := map[string]int{"url": 3}; !reflect.DeepEqual(report.Removed, expected) {
+		t.Errorf("expected %v removed, got %v", expected, report.Removed)
+	}
+	if report.Input != string(before) || report.Output == report.Input {
+		t.Error("expected the report to hold the input and the trimmed output")
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package gocdp
+
+import (
+	"os"
+	"syscall"
+)
+
+// chown changes the owner of the file to the owner in info. Permission errors are ignored
+// since only root can give away files
+func chown(file string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	err := os.Chown(file, int(stat.Uid), int(stat.Gid))
+	if err != nil && os.IsPermission(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+package gocdp
+
+import "os"
+
+// chown is a no-op on Windows, which has no Unix file ownership
+func chown(file string, info os.FileInfo) error {
+	return nil
+}
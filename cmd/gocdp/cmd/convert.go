@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NoF0rte/gocdp"
+	"github.com/spf13/cobra"
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert files...",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Convert results to another tool's format",
+	Example: `
+
+gocdp convert --to ffuf-json ferox.json dirsearch.csv -o ffuf.json
+
+Convert the feroxbuster and dirsearch results into a single ffuf JSON file
+
+
+gocdp convert --to gobuster ffuf.json
+
+Print the ffuf results as gobuster output
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, _ := cmd.Flags().GetString("to")
+		output, _ := cmd.Flags().GetString("output")
+
+		render, err := gocdp.ParseRender(to)
+		if err != nil {
+			return err
+		}
+
+		var files []string
+		for _, arg := range args {
+			if arg == "-" {
+				scanner := bufio.NewScanner(os.Stdin)
+				for scanner.Scan() {
+					files = append(files, scanner.Text())
+				}
+			} else {
+				files = append(files, arg)
+			}
+		}
+
+		results, err := newCDP(cmd).SmartParseFiles(files)
+		if err != nil {
+			return err
+		}
+
+		rendered, err := render(results, gocdp.ScanInfo{})
+		if err != nil {
+			return err
+		}
+		return writeRendered(output, rendered)
+	},
+}
+
+// writeRendered writes the rendered results to the output file, or stdout when it is empty
+func writeRendered(output string, rendered string) error {
+	if output == "" {
+		fmt.Print(rendered)
+		if !strings.HasSuffix(rendered, "\n") {
+			fmt.Println()
+		}
+		return nil
+	}
+	return os.WriteFile(output, []byte(rendered), 0644)
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().StringP("to", "t", "", fmt.Sprintf("The format to convert to (%s)", strings.Join(gocdp.RenderNames(), "|")))
+	convertCmd.MarkFlagRequired("to")
+	convertCmd.RegisterFlagCompletionFunc("to", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return gocdp.RenderNames(), cobra.ShellCompDirectiveDefault
+	})
+	convertCmd.Flags().StringP("output", "o", "", "Write the converted results to this file instead of stdout")
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedDiff returns the unified diff between a and b, or an empty string if they are the same
+func unifiedDiff(fromName string, toName string, a string, b string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	writer := bytes.NewBuffer(nil)
+	fmt.Fprintf(writer, "--- %s\n+++ %s\n", fromName, toName)
+
+	// aLines and bLines are the line numbers before each op
+	aLines := make([]int, len(ops)+1)
+	bLines := make([]int, len(ops)+1)
+	for i, op := range ops {
+		aLines[i+1] = aLines[i]
+		bLines[i+1] = bLines[i]
+		if op.kind != '+' {
+			aLines[i+1]++
+		}
+		if op.kind != '-' {
+			bLines[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend the hunk until there are more than two contexts worth of unchanged lines
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+
+			if next == len(ops) || next-end > diffContext*2 {
+				end += diffContext
+				if end > len(ops) {
+					end = len(ops)
+				}
+				break
+			}
+			end = next
+		}
+
+		fmt.Fprintf(writer, "@@ -%s +%s @@\n", hunkRange(aLines[start], aLines[end]-aLines[start]), hunkRange(bLines[start], bLines[end]-bLines[start]))
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(writer, "%c%s\n", op.kind, op.line)
+		}
+
+		i = end
+	}
+
+	return writer.String()
+}
+
+func hunkRange(start int, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines is a patience diff of the lines, which works well for the mostly removed lines of a trim
+func diffLines(a []string, b []string) []diffOp {
+	var ops []diffOp
+
+	// Common prefix
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		ops = append(ops, diffOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+
+	// Common suffix
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+
+	var suffix []diffOp
+	for _, line := range a[len(a)-n:] {
+		suffix = append(suffix, diffOp{' ', line})
+	}
+	a, b = a[:len(a)-n], b[:len(b)-n]
+
+	anchors := patienceAnchors(a, b)
+	if len(anchors) == 0 {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return append(ops, suffix...)
+	}
+
+	aStart, bStart := 0, 0
+	for _, anchor := range anchors {
+		ops = append(ops, diffLines(a[aStart:anchor[0]], b[bStart:anchor[1]])...)
+		ops = append(ops, diffOp{' ', a[anchor[0]]})
+		aStart, bStart = anchor[0]+1, anchor[1]+1
+	}
+	ops = append(ops, diffLines(a[aStart:], b[bStart:])...)
+
+	return append(ops, suffix...)
+}
+
+// patienceAnchors returns the longest increasing sequence of the lines which are unique in both a and b
+func patienceAnchors(a []string, b []string) [][2]int {
+	counts := make(map[string][2]int)
+	positions := make(map[string][2]int)
+	for i, line := range a {
+		c := counts[line]
+		c[0]++
+		counts[line] = c
+
+		p := positions[line]
+		p[0] = i
+		positions[line] = p
+	}
+	for i, line := range b {
+		c := counts[line]
+		c[1]++
+		counts[line] = c
+
+		p := positions[line]
+		p[1] = i
+		positions[line] = p
+	}
+
+	var unique [][2]int
+	for line, c := range counts {
+		if c[0] == 1 && c[1] == 1 {
+			unique = append(unique, positions[line])
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		return unique[i][0] < unique[j][0]
+	})
+
+	// Longest increasing subsequence of the positions in b
+	var tails []int
+	prev := make([]int, len(unique))
+	for i, pair := range unique {
+		n := sort.Search(len(tails), func(j int) bool {
+			return unique[tails[j]][1] >= pair[1]
+		})
+
+		prev[i] = -1
+		if n > 0 {
+			prev[i] = tails[n-1]
+		}
+
+		if n == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[n] = i
+		}
+	}
+
+	if len(tails) == 0 {
+		return nil
+	}
+
+	anchors := make([][2]int, len(tails))
+	for i, k := len(tails)-1, tails[len(tails)-1]; i >= 0; i, k = i-1, prev[k] {
+		anchors[i] = unique[k]
+	}
+	return anchors
+}
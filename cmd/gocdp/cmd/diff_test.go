@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n"
+	b := "a\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nX\nn\n"
+
+	expected := `--- a.txt
++++ b.txt
+@@ -1,5 +1,4 @@
+ a
+-b
+ c
+ d
+ e
+@@ -10,5 +9,5 @@
+ j
+ k
+ l
+-m
++X
+ n
+`
+	if diff := unifiedDiff("a.txt", "b.txt", a, b); diff != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, diff)
+	}
+}
+
+func TestUnifiedDiffEdges(t *testing.T) {
+	if diff := unifiedDiff("a", "b", "same\n", "same\n"); diff != "" {
+		t.Errorf("expected no diff for the same input, got\n%s", diff)
+	}
+
+	expected := "--- a\n+++ b\n@@ -1 +0,0 @@\n-x\n"
+	if diff := unifiedDiff("a", "b", "x\n", ""); diff != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, diff)
+	}
+
+	expected = "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n"
+	if diff := unifiedDiff("a", "b", "", "x\ny\n"); diff != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, diff)
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	tests := []struct {
+		start    int
+		length   int
+		expected string
+	}{
+		{0, 0, "0,0"},
+		{4, 0, "4,0"},
+		{0, 1, "1"},
+		{9, 5, "10,5"},
+	}
+
+	for _, test := range tests {
+		if r := hunkRange(test.start, test.length); r != test.expected {
+			t.Errorf("hunkRange(%d, %d): expected %s, got %s", test.start, test.length, test.expected, r)
+		}
+	}
+}
+
+// applyOps returns the lines before and after the ops
+func applyOps(ops []diffOp) ([]string, []string) {
+	a := []string{}
+	b := []string{}
+	for _, op := range ops {
+		if op.kind != '+' {
+			a = append(a, op.line)
+		}
+		if op.kind != '-' {
+			b = append(b, op.line)
+		}
+	}
+	return a, b
+}
+
+func TestDiffLinesRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		var a []string
+		for j := random.Intn(30); j > 0; j-- {
+			// Few distinct lines so there are duplicates as well as unique anchors
+			a = append(a, fmt.Sprint(random.Intn(12)))
+		}
+
+		// Remove and add some lines, the same as a trim with the occasional rewritten line
+		b := []string{}
+		for _, line := range a {
+			switch random.Intn(5) {
+			case 0:
+			case 1:
+				b = append(b, line, "new")
+			default:
+				b = append(b, line)
+			}
+		}
+
+		ops := diffLines(a, b)
+		actualA, actualB := applyOps(ops)
+		if len(a) == 0 {
+			a = []string{}
+		}
+		if !reflect.DeepEqual(actualA, a) || !reflect.DeepEqual(actualB, b) {
+			t.Fatalf("the diff of\n%s\nand\n%s\ndoes not apply", strings.Join(a, ","), strings.Join(b, ","))
+		}
+	}
+}
+
+func TestDiffLinesOnlyRemovals(t *testing.T) {
+	a := []string{"header", "r1", "r2", "r3", "r4", "footer"}
+	b := []string{"header", "r2", "r4", "footer"}
+
+	for _, op := range diffLines(a, b) {
+		if op.kind == '+' {
+			t.Errorf("a trim which only removes lines should not add %q", op.line)
+		}
+	}
+}
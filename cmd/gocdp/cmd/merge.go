@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NoF0rte/gocdp"
+	"github.com/spf13/cobra"
+)
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge files...",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Merge results from many files into one file",
+	Example: `
+
+gocdp merge --to ffuf-json -o merged.json scans/*
+
+Merge the results of every scan into a single ffuf JSON file, keeping the richest result for each URL
+
+
+gocdp merge --to gobuster --key url-path ffuf.json ferox.json
+
+Merge the ffuf and feroxbuster results into gobuster output, treating URLs differing only by the query string as the same
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, _ := cmd.Flags().GetString("to")
+		output, _ := cmd.Flags().GetString("output")
+		keyName, _ := cmd.Flags().GetString("key")
+
+		render, err := gocdp.ParseRender(to)
+		if err != nil {
+			return err
+		}
+
+		key, err := gocdp.ParseKey(keyName)
+		if err != nil {
+			return err
+		}
+
+		var files []string
+		for _, arg := range args {
+			if arg == "-" {
+				scanner := bufio.NewScanner(os.Stdin)
+				for scanner.Scan() {
+					files = append(files, scanner.Text())
+				}
+			} else {
+				files = append(files, arg)
+			}
+		}
+
+		rendered, err := newCDP(cmd).MergeFiles(files, key, render)
+		if err != nil {
+			return err
+		}
+		return writeRendered(output, rendered)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.Flags().StringP("to", "t", "", fmt.Sprintf("The format of the merged file (%s)", strings.Join(gocdp.RenderNames(), "|")))
+	mergeCmd.MarkFlagRequired("to")
+	mergeCmd.RegisterFlagCompletionFunc("to", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return gocdp.RenderNames(), cobra.ShellCompDirectiveDefault
+	})
+	mergeCmd.Flags().StringP("output", "o", "", "Write the merged file to this file instead of stdout")
+	mergeCmd.Flags().String("key", "url", fmt.Sprintf("The key duplicate results are found by (%s)", strings.Join(gocdp.KeyNames(), "|")))
+	mergeCmd.RegisterFlagCompletionFunc("key", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return gocdp.KeyNames(), cobra.ShellCompDirectiveDefault
+	})
+}
@@ -5,88 +5,105 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"os"
 	"strings"
+	"text/template"
 
 	"github.com/NoF0rte/gocdp"
 	"github.com/spf13/cobra"
 )
 
-type noopWriter struct {
-}
-
-func (w noopWriter) Write(bytes []byte) (int, error) {
-	return 0, nil
-}
-
 const (
 	groupByStatus = "status"
 	groupByRange  = "range"
+	groupByParam  = "param"
 )
 
 var validGroupByOptions = []string{
 	groupByStatus,
 	groupByRange,
+	groupByParam,
 }
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "gocdp files...",
+	Use:   "gocdp files|dirs...",
 	Short: "Content discovery parser",
 	Long: `Content discovery parser
 
-Available query functions:
+Query fields:
 
-  .IsStatus
-  .IsRedirect
-  .IsSuccess
-  .IsError
-  .IsAuthError
-  .IsRateLimit
+  url, host, path
+  status (-1 when unknown), redirect, content_type
+  length (-1 when unknown), words, lines, duration
+  params, param_location, response_file, raw
+  probed, success, error, auth_error, rate_limit
+  extra.<name> (tool specific fields e.g. extra.title and extra.tech from httpx)
+
+Query operators:
+
+  ==, !=, <, <=, >, >=
+  =~, !~ (regex), glob, contains
+  in (ranges e.g. 200..299, 500.., ..1s, tolerances e.g. 4242~10, 4242~2%, 1s~100ms
+      or lists e.g. [200, 301, 500..599], ["a", "b"] or params)
+  and, or, not (or &&, ||, !) and parentheses
+  A field on its own is true when it is set e.g. 'redirect' or 'not params'
 
 Available format fields:
 
   .Url
-  .Status
+  .Status (-1 when unknown)
   .Redirect
   .ContentType
-  .ContentLength
+  .ContentLength (-1 when unknown)
+  .Words
+  .Lines
+  .Duration
+  .Params
+  .ParamLocation (query, body or header)
+  .ResponseFile (path to the stored response e.g. from meg)
+  .Extra (tool specific fields e.g. {{.Extra.title}} and {{.Extra.tech}} from httpx, {{.Extra.note}} from nmap and nikto)
+  .Raw (the record the result was parsed from e.g. the ffuf JSON object or the gobuster line)
 `,
 	Example: `
 
-gocdp ffuf* -q '.IsSuccess' -f '{{.Url}}'
+gocdp ffuf* -q 'success' -f '{{.Url}}'
 	OR
-find ./ -name '*ffuf*' | gocdp - -q '.IsSuccess' -f '{{.Url}}'
+find ./ -name '*ffuf*' | gocdp - -q 'success' -f '{{.Url}}'
 
 Show only the URLs from the results with success status codes
 
 
-gocdp ffuf* -q '.IsRedirect' -f '{{.Redirect}}'
+gocdp ffuf* -q 'redirect' -f '{{.Redirect}}'
 
 Show the redirect URLs from the results which were redirected
 
 
-gocdp ffuf* -q '.IsRedirect' -f '{{.Url}} -> {{.Redirect}}'
+gocdp ffuf* -q 'redirect' -f '{{.Url}} -> {{.Redirect}}'
 
 Show the urls and where they redirect from the results which were redirected
 
 
-gocdp ffuf* -q 'not (or .IsRateLimit .IsError)'
+gocdp ffuf* -q 'not (rate_limit or error)'
 
 Show the JSON output of all results which weren't rate limited or errors
 
 
-gocdp ffuf* -q 'not (.IsStatus "400,429,401")'
+gocdp ffuf* -q 'not status in [400, 429, 401]'
 
 Show the JSON output of all results except the ones with status codes 400, 429, or 401
 
 
-gocdp ffuf* -q '.IsStatus "409"'
+gocdp ffuf* -q 'status == 409'
 
 Show the JSON output of only the results with the status code of 409
 
 
+gocdp ffuf* -q 'status in 300..399 and not length in 4242~2%'
+
+Show the JSON output of the redirects, except the ones within 2% of 4242 bytes
+
+
 gocdp ffuf* -g range
 
 Show the JSON output of all results, grouped by the status code ranges i.e. 200-299, 300-399, etc.
@@ -94,6 +111,41 @@ Show the JSON output of all results, grouped by the status code ranges i.e. 200-
 gocdp ffuf* -g status
 
 Show the JSON output of all results, grouped by the status code
+
+
+gocdp httpx.json -q 'extra.tech' -f '{{.Url}} {{.Extra.title}} {{.Extra.tech}}'
+
+Show the URL, title and detected technologies of the httpx results with any detected technologies
+
+
+gau example.com | gocdp --url-list /dev/stdin -q 'not probed' -f '{{.Url}}'
+
+Show the URLs from a plain URL list, such as the output of gau or waybackurls, which have not been probed
+
+
+gocdp nmap.xml nikto.json -f '{{.Url}} {{.Extra.note}}'
+
+Show the paths found by the nmap http-enum script and nikto along with their descriptions
+
+
+gocdp ffuf.json arjun.json x8.json --merge-params -q '"debug" in params' -f '{{.Url}} {{.Params}}'
+
+Show the URLs, merged with their discovered parameters, which accept the "debug" parameter
+
+
+gocdp arjun.json x8.json -g param
+
+Show the JSON output of all parameter discovery results, grouped by the parameter name
+
+
+gocdp out/ -q 'success' -f '{{.Url}} {{.ResponseFile}}'
+
+Show the URLs and stored response files of the successful results from a meg output directory
+
+
+gocdp ffuf.json -q 'status == 500' --raw
+
+Show the JSON output of the results with the status code 500 along with the ffuf JSON objects they were parsed from
 `,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -109,32 +161,22 @@ Show the JSON output of all results, grouped by the status code
 			}
 		}
 
-		results, err := gocdp.SmartParseFiles(files)
+		results, err := newCDP(cmd).SmartParseFiles(files)
 		if err != nil {
 			return err
 		}
 
+		mergeParams, _ := cmd.Flags().GetBool("merge-params")
+		if mergeParams {
+			results = results.MergeParams()
+		}
+
 		query, _ := cmd.Flags().GetString("query")
 		if query != "" {
-			var filteredResults gocdp.CDResults
-			filterTemplate := template.New("filter")
-			funcMap := make(template.FuncMap)
-			funcMap["appendMatch"] = func(match gocdp.CDResult) string {
-				filteredResults = append(filteredResults, match)
-				return ""
-			}
-
-			templateString := fmt.Sprintf(`{{range $result := .}}{{if %s}}{{appendMatch $result}}{{end}}{{end}}`, query)
-			_, err := filterTemplate.Funcs(funcMap).Parse(templateString)
+			results, err = results.Where(query)
 			if err != nil {
 				return err
 			}
-
-			err = filterTemplate.Execute(noopWriter{}, results)
-			if err != nil {
-				return err
-			}
-			results = filteredResults
 		}
 
 		unique, _ := cmd.Flags().GetBool("unique")
@@ -142,6 +184,7 @@ Show the JSON output of all results, grouped by the status code
 			results = results.UniqueByURL()
 		}
 
+		raw, _ := cmd.Flags().GetBool("raw")
 		group, _ := cmd.Flags().GetString("group")
 		format, _ := cmd.Flags().GetString("format")
 		if format != "" {
@@ -159,12 +202,18 @@ Show the JSON output of all results, grouped by the status code
 				fmt.Println(buf.String())
 			}
 		} else if group != "" {
-			var grouped map[int][]gocdp.CDResult
+			var grouped interface{}
 			switch group {
 			case groupByStatus:
 				grouped = results.GroupByStatus()
 			case groupByRange:
 				grouped = results.GroupByStatusRange()
+			case groupByParam:
+				grouped = results.GroupByParam()
+			}
+
+			if raw {
+				grouped = withRaw(grouped)
 			}
 
 			data, err := json.MarshalIndent(grouped, "", "  ")
@@ -173,7 +222,12 @@ Show the JSON output of all results, grouped by the status code
 			}
 			fmt.Println(string(data))
 		} else {
-			data, err := json.MarshalIndent(results, "", "  ")
+			var output interface{} = results
+			if raw {
+				output = withRaw(results)
+			}
+
+			data, err := json.MarshalIndent(output, "", "  ")
 			if err != nil {
 				return err
 			}
@@ -184,6 +238,66 @@ Show the JSON output of all results, grouped by the status code
 	},
 }
 
+// rawResult is a result along with the record it was parsed from, see --raw
+type rawResult struct {
+	gocdp.CDResult
+	// Raw is the JSON of JSON records, otherwise the text of the record
+	Raw interface{} `json:",omitempty"`
+}
+
+func rawResults(results []gocdp.CDResult) []rawResult {
+	raws := make([]rawResult, 0, len(results))
+	for _, result := range results {
+		raw := rawResult{
+			CDResult: result,
+		}
+
+		source := result.Source()
+		switch source.Format() {
+		case gocdp.RecordNone:
+		case gocdp.RecordJSON:
+			raw.Raw = json.RawMessage(source.Raw())
+		default:
+			raw.Raw = source.String()
+		}
+		raws = append(raws, raw)
+	}
+	return raws
+}
+
+// withRaw adds the records to the results, or to each group of results
+func withRaw(v interface{}) interface{} {
+	switch v := v.(type) {
+	case gocdp.CDResults:
+		return rawResults(v)
+	case map[int][]gocdp.CDResult:
+		grouped := make(map[int][]rawResult)
+		for key, group := range v {
+			grouped[key] = rawResults(group)
+		}
+		return grouped
+	case map[string][]gocdp.CDResult:
+		grouped := make(map[string][]rawResult)
+		for key, group := range v {
+			grouped[key] = rawResults(group)
+		}
+		return grouped
+	}
+	return v
+}
+
+// newCDP creates a CDP configured from the persistent flags
+func newCDP(cmd *cobra.Command) *gocdp.CDP {
+	var options []gocdp.Option
+
+	urlList, _ := cmd.Flags().GetBool("url-list")
+	if urlList {
+		options = append(options, gocdp.AdditionalParsers(gocdp.URLListParser{}))
+	}
+
+	return gocdp.New(options...)
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -191,9 +305,13 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().Bool("url-list", false, "Parse files which are plain lists of URLs e.g. the output of gau or waybackurls")
+
 	rootCmd.Flags().Bool("unique", false, "De-duplicate the results by URL")
-	rootCmd.Flags().StringP("format", "f", "", "golang text/template format to be applied on each result")
-	rootCmd.Flags().StringP("query", "q", "", "golang text/template used to filter the results")
+	rootCmd.Flags().Bool("raw", false, "Include the record each result was parsed from in the JSON output")
+	rootCmd.Flags().Bool("merge-params", false, "Merge parameter discovery results into the results for the same URL")
+	rootCmd.Flags().StringP("format", "f", "", "golang text/template format to be applied on each result. The output is not HTML escaped, use e.g. {{html .Url}} to escape it")
+	rootCmd.Flags().StringP("query", "q", "", "expression used to filter the results e.g. 'status in 200..299 and not redirect'")
 	rootCmd.Flags().StringP("group", "g", "", fmt.Sprintf("group the results by (%s)", strings.Join(validGroupByOptions, "|")))
 	rootCmd.RegisterFlagCompletionFunc("group", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return validGroupByOptions, cobra.ShellCompDirectiveDefault
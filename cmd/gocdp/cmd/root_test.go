@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/NoF0rte/gocdp"
+)
+
+var exampleQueryRegex = regexp.MustCompile(`-q '([^']*)'`)
+
+func TestExampleQueries(t *testing.T) {
+	readme, err := os.ReadFile("../../../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	examples := map[string]string{
+		"root":   rootCmd.Example,
+		"trim":   trimCmd.Example,
+		"README": string(readme),
+	}
+
+	for name, example := range examples {
+		matches := exampleQueryRegex.FindAllStringSubmatch(example, -1)
+		if len(matches) == 0 {
+			t.Errorf("%s: no queries found", name)
+		}
+
+		for _, match := range matches {
+			if _, err := gocdp.ParseExpression(match[1]); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestWithRaw(t *testing.T) {
+	ffuf, err := gocdp.RenderFfufJSON(gocdp.CDResults{{Url: "http://t/a", Status: 200}}, gocdp.ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []string{
+		ffuf,
+		"http://t/b (Status: 403) [Size: 12]\n",
+	}
+
+	var results gocdp.CDResults
+	for _, input := range inputs {
+		parsed, err := gocdp.SmartParse(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, parsed...)
+	}
+	results = append(results, gocdp.CDResult{Url: "http://t/c", Status: 200})
+
+	data, err := json.Marshal(withRaw(results))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output []map[string]interface{}
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatal(err)
+	}
+
+	// JSON records are embedded as objects, text records as strings and results without a record have no Raw
+	if raw, ok := output[0]["Raw"].(map[string]interface{}); !ok || raw["url"] != "http://t/a" {
+		t.Errorf("expected the ffuf record as an object, got %v", output[0]["Raw"])
+	}
+	if raw := output[1]["Raw"]; raw != "http://t/b (Status: 403) [Size: 12]" {
+		t.Errorf("expected the gobuster line, got %v", raw)
+	}
+	if _, ok := output[2]["Raw"]; ok {
+		t.Errorf("expected no Raw without a record, got %v", output[2]["Raw"])
+	}
+	if output[1]["Url"] != "http://t/b" {
+		t.Errorf("expected the result fields next to Raw, got %v", output[1])
+	}
+
+	grouped, ok := withRaw(results.GroupByStatus()).(map[int][]rawResult)
+	if !ok || len(grouped[200]) != 2 || len(grouped[403]) != 1 {
+		t.Errorf("expected the groups with their records, got %v", grouped)
+	}
+}
+
+func TestFormatRaw(t *testing.T) {
+	results, err := gocdp.SmartParse(strings.NewReader("http://t/b?x=<1> (Status: 403) [Size: 12]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formatTemplate, err := template.New("format").Parse("{{.Raw}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := formatTemplate.Execute(buf, results[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// text/template writes the record as is
+	if expected := "http://t/b?x=<1> (Status: 403) [Size: 12]"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
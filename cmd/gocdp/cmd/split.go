@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/NoF0rte/gocdp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitByKeys = map[string]gocdp.KeyFunc{
+		"host":         gocdp.KeyHost,
+		"status":       gocdp.KeyStatus,
+		"range":        gocdp.KeyStatusRange,
+		"content-type": gocdp.KeyContentType,
+	}
+	validSplitByOptions = []string{"content-type", "host", "range", "status"}
+
+	unsafeFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// splitCmd represents the split command
+var splitCmd = &cobra.Command{
+	Use:   "split files...",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Split results into one file per host, status, status range or content type",
+	Example: `
+
+gocdp split --by host -o hosts/ ffuf.json
+
+Split the ffuf results into one ffuf file per host e.g. hosts/ffuf.app.example.com.json
+
+
+gocdp split --by range --to gobuster -o ranges/ ffuf.json ferox.json
+
+Split the ffuf and feroxbuster results into one gobuster file per status range e.g. ranges/2xx.txt
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		by, _ := cmd.Flags().GetString("by")
+		to, _ := cmd.Flags().GetString("to")
+		output, _ := cmd.Flags().GetString("output")
+
+		key, ok := splitByKeys[by]
+		if !ok {
+			return fmt.Errorf("unknown split '%s', expected one of: %s", by, strings.Join(validSplitByOptions, ", "))
+		}
+
+		var files []string
+		for _, arg := range args {
+			if arg == "-" {
+				scanner := bufio.NewScanner(os.Stdin)
+				for scanner.Scan() {
+					files = append(files, scanner.Text())
+				}
+			} else {
+				files = append(files, arg)
+			}
+		}
+
+		err := os.MkdirAll(output, 0755)
+		if err != nil {
+			return err
+		}
+
+		used := make(splitFiles)
+		if to == "" {
+			for _, file := range files {
+				outputs, err := newCDP(cmd).SplitFile(file, key)
+				if err != nil {
+					return fmt.Errorf("%s: %w", file, err)
+				}
+
+				ext := filepath.Ext(file)
+				stem := strings.TrimSuffix(filepath.Base(file), ext)
+				// The groups are sorted so the same group always gets the same file name
+				var groups []string
+				for group := range outputs {
+					groups = append(groups, group)
+				}
+				sort.Strings(groups)
+
+				for _, group := range groups {
+					err = writeSplit(used.name(filepath.Join(output, fmt.Sprintf("%s.%s%s", stem, splitFileName(group), ext))), outputs[group])
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+
+		render, err := gocdp.ParseRender(to)
+		if err != nil {
+			return err
+		}
+
+		results, err := newCDP(cmd).SmartParseFiles(files)
+		if err != nil {
+			return err
+		}
+
+		grouped := results.GroupBy(key)
+		var groups []string
+		for group := range grouped {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+
+		for _, group := range groups {
+			rendered, err := render(grouped[group], gocdp.ScanInfo{})
+			if err != nil {
+				return err
+			}
+
+			err = writeSplit(used.name(filepath.Join(output, splitFileName(group)+renderExt(to))), rendered)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// splitFileName makes the group safe to use in a file name
+func splitFileName(group string) string {
+	if group == fmt.Sprint(gocdp.StatusUnknown) {
+		return "unknown"
+	}
+
+	name := unsafeFileNameRegex.ReplaceAllString(group, "_")
+	if strings.Trim(name, "._") == "" {
+		return "none"
+	}
+	return name
+}
+
+// splitFiles are the file names already used by the split
+type splitFiles map[string]bool
+
+// name returns the file, or the file with a counter added before the extension e.g. text_html-2.json when it is already used.
+// Different groups can have the same safe file name e.g. text/html and text_html, or the same group from files with the same name
+func (used splitFiles) name(file string) string {
+	ext := filepath.Ext(file)
+	stem := strings.TrimSuffix(file, ext)
+
+	name := file
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	used[name] = true
+	return name
+}
+
+// renderExt returns the file extension for the format e.g. .json for ffuf-json
+func renderExt(format string) string {
+	parts := strings.Split(format, "-")
+	switch ext := parts[len(parts)-1]; ext {
+	case "json", "csv", "xml", "md":
+		return "." + ext
+	}
+	return ".txt"
+}
+
+func writeSplit(file string, data string) error {
+	fmt.Println(file)
+	return os.WriteFile(file, []byte(data), 0644)
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+
+	splitCmd.Flags().String("by", "host", fmt.Sprintf("Split the results by (%s)", strings.Join(validSplitByOptions, "|")))
+	splitCmd.RegisterFlagCompletionFunc("by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return validSplitByOptions, cobra.ShellCompDirectiveDefault
+	})
+	splitCmd.Flags().StringP("to", "t", "", fmt.Sprintf("Write the split files in this format instead of the original format (%s)", strings.Join(gocdp.RenderNames(), "|")))
+	splitCmd.RegisterFlagCompletionFunc("to", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return gocdp.RenderNames(), cobra.ShellCompDirectiveDefault
+	})
+	splitCmd.Flags().StringP("output", "o", "", "The directory the split files are written to")
+	splitCmd.MarkFlagRequired("output")
+}
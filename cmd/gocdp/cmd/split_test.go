@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NoF0rte/gocdp"
+)
+
+func TestSplitFilesName(t *testing.T) {
+	used := make(splitFiles)
+
+	names := []string{
+		used.name("out/f.text_html.json"),
+		used.name("out/f.text_html.json"),
+		used.name("out/f.text_html-2.json"),
+		used.name("out/f.text_html.json"),
+	}
+
+	expected := []string{"out/f.text_html.json", "out/f.text_html-2.json", "out/f.text_html-2-2.json", "out/f.text_html-3.json"}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], names[i])
+		}
+	}
+}
+
+func TestSplitCollidingGroups(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f.json")
+
+	output, err := gocdp.RenderFfufJSON(gocdp.CDResults{
+		{Url: "http://t/a", Status: 200, ContentType: "text/html"},
+		{Url: "http://t/b", Status: 200, ContentType: "text_html"},
+	}, gocdp.ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(file, []byte(output), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out")
+	rootCmd.SetArgs([]string{"split", "--by", "content-type", "-o", out, file})
+	err = rootCmd.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{"f.text_html.json": "http://t/a", "f.text_html-2.json": "http://t/b"} {
+		results, err := gocdp.SmartParseFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(results) != 1 || results[0].Url != expected {
+			t.Errorf("%s: expected only %s, got %d results", name, expected, len(results))
+		}
+	}
+}
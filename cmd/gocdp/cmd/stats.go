@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 
 	"github.com/NoF0rte/gocdp"
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -32,7 +33,7 @@ var statsCmd = &cobra.Command{
 		byFile, _ := cmd.Flags().GetBool("by-file")
 		if byFile {
 			for _, file := range files {
-				results, err := gocdp.SmartParseFiles([]string{file})
+				results, err := newCDP(cmd).SmartParseFiles([]string{file})
 				if err != nil {
 					return err
 				}
@@ -42,7 +43,7 @@ var statsCmd = &cobra.Command{
 				fmt.Println()
 			}
 		} else {
-			results, err := gocdp.SmartParseFiles(files)
+			results, err := newCDP(cmd).SmartParseFiles(files)
 			if err != nil {
 				return err
 			}
@@ -67,6 +68,20 @@ func (s *stats) incContentType(contentType string) {
 	s.contentTypes[contentType] += 1
 }
 
+func statusLabel(status int) string {
+	if status == gocdp.StatusUnknown {
+		return "unknown"
+	}
+	return strconv.Itoa(status)
+}
+
+func lengthLabel(length int) string {
+	if length == gocdp.LengthUnknown {
+		return "unknown"
+	}
+	return strconv.Itoa(length)
+}
+
 func displayStatsTables(grouped map[int][]gocdp.CDResult) {
 	totalsWriter := table.NewWriter()
 	totalsWriter.AppendHeader(table.Row{"Status", "Total"})
@@ -87,7 +102,7 @@ func displayStatsTables(grouped map[int][]gocdp.CDResult) {
 		}
 
 		totalsWriter.AppendRow(table.Row{
-			status,
+			statusLabel(status),
 			len(results),
 		})
 
@@ -99,12 +114,12 @@ func displayStatsTables(grouped map[int][]gocdp.CDResult) {
 		sizeWriter := table.NewWriter()
 		// sizeWriter.AppendHeader(table.Row{"Size", "Total"})
 		// sizeWriter.SetTitle(fmt.Sprintf("\"%d\" Size Stats", status))
-		sizeWriter.SetTitle(fmt.Sprintf("Status: %d", status))
+		sizeWriter.SetTitle(fmt.Sprintf("Status: %s", statusLabel(status)))
 
 		sizeWriter.AppendRow(table.Row{"SIZE", "TOTAL"})
 		sizeWriter.AppendSeparator()
 		for size, total := range stat.sizes {
-			sizeWriter.AppendRow(table.Row{size, total})
+			sizeWriter.AppendRow(table.Row{lengthLabel(size), total})
 		}
 		sizeWriter.AppendSeparator()
 
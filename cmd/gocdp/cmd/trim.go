@@ -2,7 +2,13 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/NoF0rte/gocdp"
 	"github.com/spf13/cobra"
@@ -13,14 +19,113 @@ var trimCmd = &cobra.Command{
 	Use:   "trim files...",
 	Args:  cobra.MinimumNArgs(1),
 	Short: "Trim/filter results",
+	Example: `
+
+gocdp trim ffuf.json -s 403 -s 404 --backup
+
+Remove the results with the status codes 403 and 404, keeping the original file as ffuf.json.orig
+
+
+gocdp trim ffuf* -m 20 --output trimmed/
+
+Keep at most 20 results per status code, writing the trimmed files to the trimmed directory
+
+
+gocdp trim ffuf.json -s 403 -m 20 --dry-run --diff
+
+Show what would be removed by each filter along with the diff, without changing the file
+
+
+gocdp trim ffuf.json --keep -o and -s 200,301 -u /api/
+
+Keep only the results with the status codes 200 or 301 whose URLs match /api/
+
+
+gocdp trim ffuf.json -s '!200' -u '!/api/' -o and
+
+Remove the results which neither have the status code 200 nor match /api/
+
+
+gocdp trim ffuf.json -q 'error and length > 5000' -q 'rate_limit'
+
+Remove the error results larger than 5000 bytes as well as the rate limited results
+
+
+gocdp trim ffuf.json -s 500-599 -l 4242~2%
+
+Remove the results with 5xx status codes as well as the results within 2% of 4242 bytes
+
+
+gocdp trim ffuf.json -m 5 --max-by status-length
+
+Keep at most 5 results for each status code and content length, thinning out identical wildcard responses
+
+
+gocdp trim ffuf.json -m 20 --sample diverse
+
+Keep at most 20 results per status code, preferring distinct lengths, content types and path depths over the first 20
+
+
+gocdp trim ffuf* --auto --auto-count 20 --auto-samples 2
+
+Remove the clusters of 20 or more results with the same status code and content length, keeping 2 of each
+
+
+gocdp trim ffuf.json -u '/static/?' --literal -i
+
+Remove the results whose URLs contain the text /static/? in any case
+
+
+gocdp trim evidence/* -s 404 --all-or-nothing --backup
+
+Remove the 404 results from every file, or from none of them if any file cannot be trimmed
+
+
+gocdp trim new/*.json --against 'old/*.json' --against 'new/*.json'
+
+Remove the results from the new scans which were already found by the old scans or an earlier new scan, keeping the first copy of each
+
+
+gocdp trim ffuf.json -s 404 --remove-responses --dry-run
+
+List the stored responses from ffuf -od which would be deleted along with the 404 results
+`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		max, _ := cmd.Flags().GetInt("max")
+		maxBy, _ := cmd.Flags().GetString("max-by")
+		sampleBy, _ := cmd.Flags().GetString("sample")
+		seed, _ := cmd.Flags().GetInt64("seed")
 		redirects, _ := cmd.Flags().GetStringSlice("redirect")
 		urls, _ := cmd.Flags().GetStringSlice("url")
 		contentTypes, _ := cmd.Flags().GetStringSlice("content-type")
-		statusCodes, _ := cmd.Flags().GetIntSlice("status")
-		lengths, _ := cmd.Flags().GetIntSlice("length")
+		statusCodes, _ := cmd.Flags().GetStringSlice("status")
+		lengths, _ := cmd.Flags().GetStringSlice("length")
+		words, _ := cmd.Flags().GetStringSlice("words")
+		lines, _ := cmd.Flags().GetStringSlice("lines")
+		durations, _ := cmd.Flags().GetStringSlice("duration")
+		keep, _ := cmd.Flags().GetBool("keep")
+		literal, _ := cmd.Flags().GetBool("literal")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		queries, _ := cmd.Flags().GetStringArray("query")
 		operator, _ := cmd.Flags().GetString("operator")
+		backup, _ := cmd.Flags().GetBool("backup")
+		backupSuffix, _ := cmd.Flags().GetString("backup-suffix")
+		outputDir, _ := cmd.Flags().GetString("output")
+		outputSuffix, _ := cmd.Flags().GetString("suffix")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		diff, _ := cmd.Flags().GetBool("diff")
+		verify, _ := cmd.Flags().GetBool("verify")
+		noVerify, _ := cmd.Flags().GetBool("no-verify")
+		allOrNothing, _ := cmd.Flags().GetBool("all-or-nothing")
+		removeResponses, _ := cmd.Flags().GetBool("remove-responses")
+		moveResponses, _ := cmd.Flags().GetString("move-responses")
+		against, _ := cmd.Flags().GetStringSlice("against")
+		againstKey, _ := cmd.Flags().GetString("against-key")
+		auto, _ := cmd.Flags().GetBool("auto")
+		autoBy, _ := cmd.Flags().GetString("auto-by")
+		autoCount, _ := cmd.Flags().GetInt("auto-count")
+		autoShare, _ := cmd.Flags().GetFloat64("auto-share")
+		autoSamples, _ := cmd.Flags().GetInt("auto-samples")
 
 		var files []string
 		for _, arg := range args {
@@ -42,41 +147,308 @@ var trimCmd = &cobra.Command{
 
 		opts = append(opts, gocdp.WithFilterOperator(op))
 		if max > 0 {
-			opts = append(opts, gocdp.WithMaxResults(max))
+			key, err := gocdp.ParseKey(maxBy)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, gocdp.WithMaxResultsBy(max, key))
 		}
 
-		if len(redirects) > 0 {
-			opts = append(opts, gocdp.WithFilterRedirect(redirects...))
+		if sampleBy == "random" && !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+			fmt.Fprintf(os.Stderr, "random sample seed: %d, use --seed %d to keep the same results\n", seed, seed)
 		}
 
-		if len(urls) > 0 {
-			opts = append(opts, gocdp.WithFilterURL(urls...))
+		sampler, err := gocdp.ParseSampler(sampleBy, seed)
+		if err != nil {
+			return err
 		}
+		opts = append(opts, gocdp.WithSampler(sampler))
 
-		if len(contentTypes) > 0 {
-			opts = append(opts, gocdp.WithFilterContentType(contentTypes...))
+		if keep {
+			opts = append(opts, gocdp.WithKeepMode())
 		}
 
-		if len(statusCodes) > 0 {
-			opts = append(opts, gocdp.WithFilterStatus(statusCodes...))
+		if len(against) > 0 {
+			key, err := gocdp.ParseKey(againstKey)
+			if err != nil {
+				return err
+			}
+
+			var referenceFiles []string
+			for _, pattern := range against {
+				matches, err := filepath.Glob(pattern)
+				if err != nil {
+					return err
+				}
+
+				if len(matches) == 0 {
+					return fmt.Errorf("no files found for '%s'", pattern)
+				}
+				referenceFiles = append(referenceFiles, matches...)
+			}
+
+			reference, err := newCDP(cmd).SmartParseFiles(referenceFiles)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, gocdp.WithFilterAgainst(reference, key))
+		}
+
+		for _, query := range queries {
+			opts = append(opts, gocdp.WithFilterWhere(query))
+		}
+
+		mode := gocdp.MatchRegex
+		if literal {
+			mode |= gocdp.MatchLiteral
+		}
+		if ignoreCase {
+			mode |= gocdp.MatchIgnoreCase
 		}
 
-		if len(lengths) > 0 {
-			opts = append(opts, gocdp.WithFilterLength(lengths...))
+		opts = append(opts, filterOptions(redirects, func(patterns ...string) gocdp.TrimOption {
+			return gocdp.WithFilterRedirectMatch(mode, patterns...)
+		})...)
+		opts = append(opts, filterOptions(urls, func(patterns ...string) gocdp.TrimOption {
+			return gocdp.WithFilterURLMatch(mode, patterns...)
+		})...)
+		opts = append(opts, filterOptions(contentTypes, gocdp.WithFilterContentType)...)
+
+		rangeFilters := []struct {
+			values []string
+			filter func(...gocdp.IntRange) gocdp.TrimOption
+		}{
+			{statusCodes, gocdp.WithFilterStatusRange},
+			{lengths, gocdp.WithFilterLengthRange},
+			{words, gocdp.WithFilterWordsRange},
+			{lines, gocdp.WithFilterLinesRange},
+		}
+		for _, f := range rangeFilters {
+			rangeOpts, err := rangeFilterOptions(f.values, f.filter)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, rangeOpts...)
 		}
 
-		return gocdp.SmartTrimFiles(files, opts)
+		durationOpts, err := durationFilterOptions(durations)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, durationOpts...)
+
+		if backup {
+			opts = append(opts, gocdp.WithBackup(backupSuffix))
+		}
+
+		if outputDir != "" {
+			opts = append(opts, gocdp.WithOutputDir(outputDir))
+		}
+
+		if outputSuffix != "" {
+			opts = append(opts, gocdp.WithOutputSuffix(outputSuffix))
+		}
+
+		if dryRun {
+			opts = append(opts, gocdp.WithDryRun())
+		}
+
+		if verify && noVerify {
+			return errors.New("--verify and --no-verify cannot be used together")
+		}
+
+		if noVerify && allOrNothing {
+			return errors.New("--no-verify cannot be used with --all-or-nothing, which always verifies the output")
+		}
+
+		if noVerify {
+			opts = append(opts, gocdp.WithSkipVerify())
+		}
+
+		if allOrNothing {
+			opts = append(opts, gocdp.WithAllOrNothing())
+		}
+
+		if removeResponses && moveResponses != "" {
+			return errors.New("--remove-responses and --move-responses cannot be used together")
+		}
+
+		if removeResponses {
+			opts = append(opts, gocdp.WithRemoveResponses())
+		}
+
+		if moveResponses != "" {
+			opts = append(opts, gocdp.WithMoveResponses(moveResponses))
+		}
+
+		if auto {
+			key, err := gocdp.ParseKey(autoBy)
+			if err != nil {
+				return err
+			}
+
+			opts = append(opts, gocdp.WithAutoCalibration(gocdp.AutoCalibration{
+				Key:      key,
+				MinCount: autoCount,
+				MinShare: autoShare / 100,
+				Samples:  autoSamples,
+			}))
+		}
+
+		if dryRun || diff || verify || auto {
+			opts = append(opts, gocdp.WithReport(func(report *gocdp.TrimReport) {
+				if dryRun {
+					printTrimReport(report)
+
+					if removeResponses || moveResponses != "" {
+						for _, file := range report.ResponseFiles {
+							fmt.Printf("  response: %s\n", file)
+						}
+					}
+				}
+
+				if verify {
+					fmt.Printf("%s: verified %d results\n", report.File, report.Kept)
+				}
+
+				for _, cluster := range report.Clusters {
+					fmt.Printf("%s: removed %d of %d results with %s %s e.g. %s\n", report.File, cluster.Removed, cluster.Total, autoBy, cluster.Key, cluster.Sample)
+				}
+
+				if diff {
+					fmt.Print(unifiedDiff(report.File, report.OutputFile, report.Input, report.Output))
+				}
+			}))
+		}
+
+		return newCDP(cmd).SmartTrimFiles(files, opts)
 	},
 }
 
+// filterOptions creates the filter options for the flag values. Values starting with ! are added as a negated filter
+func filterOptions(values []string, filter func(...string) gocdp.TrimOption) []gocdp.TrimOption {
+	var positive []string
+	var negative []string
+	for _, value := range values {
+		if strings.HasPrefix(value, "!") {
+			negative = append(negative, value[1:])
+		} else {
+			positive = append(positive, value)
+		}
+	}
+
+	var opts []gocdp.TrimOption
+	if len(positive) > 0 {
+		opts = append(opts, filter(positive...))
+	}
+
+	if len(negative) > 0 {
+		opts = append(opts, gocdp.Not(filter(negative...)))
+	}
+	return opts
+}
+
+// rangeFilterOptions creates the filter options for the range flag values e.g. 404, 500-599 or 4242~2%
+func rangeFilterOptions(values []string, filter func(...gocdp.IntRange) gocdp.TrimOption) ([]gocdp.TrimOption, error) {
+	for _, value := range values {
+		_, err := gocdp.ParseIntRange(strings.TrimPrefix(value, "!"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filterOptions(values, func(values ...string) gocdp.TrimOption {
+		var ranges []gocdp.IntRange
+		for _, value := range values {
+			r, _ := gocdp.ParseIntRange(value)
+			ranges = append(ranges, r)
+		}
+		return filter(ranges...)
+	}), nil
+}
+
+// durationFilterOptions creates the filter options for the duration range flag values e.g. 1s- or 100ms-500ms
+func durationFilterOptions(values []string) ([]gocdp.TrimOption, error) {
+	for _, value := range values {
+		_, err := gocdp.ParseDurationRange(strings.TrimPrefix(value, "!"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filterOptions(values, func(values ...string) gocdp.TrimOption {
+		var ranges []gocdp.DurationRange
+		for _, value := range values {
+			r, _ := gocdp.ParseDurationRange(value)
+			ranges = append(ranges, r)
+		}
+		return gocdp.WithFilterDurationRange(ranges...)
+	}), nil
+}
+
+func printTrimReport(report *gocdp.TrimReport) {
+	fmt.Printf("%s: kept %d of %d results, removed %d\n", report.File, report.Kept, report.Total, report.Total-report.Kept)
+
+	var names []string
+	for name := range report.Removed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("  %s: %d\n", name, report.Removed[name])
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(trimCmd)
 
-	trimCmd.Flags().IntP("max", "m", 0, "Maximum number of results per status code.")
-	trimCmd.Flags().StringSliceP("redirect", "r", []string{}, "Regex to filter redirect URLs")
-	trimCmd.Flags().StringSliceP("url", "u", []string{}, "Regex to filter URLs")
-	trimCmd.Flags().StringSliceP("content-type", "c", []string{}, "Filter content types")
-	trimCmd.Flags().IntSliceP("status", "s", []int{}, "Filter status codes")
-	trimCmd.Flags().IntSliceP("length", "l", []int{}, "Filter content lengths")
+	trimCmd.Flags().IntP("max", "m", 0, "Maximum number of results per status code, or per --max-by key.")
+	trimCmd.Flags().String("max-by", "status", fmt.Sprintf("The key results are counted by for --max (%s)", strings.Join(gocdp.KeyNames(), "|")))
+	trimCmd.RegisterFlagCompletionFunc("max-by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return gocdp.KeyNames(), cobra.ShellCompDirectiveDefault
+	})
+	trimCmd.Flags().String("sample", "first", fmt.Sprintf("How the results kept by --max and --auto are picked (%s)", strings.Join(gocdp.SamplerNames(), "|")))
+	trimCmd.RegisterFlagCompletionFunc("sample", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return gocdp.SamplerNames(), cobra.ShellCompDirectiveDefault
+	})
+	trimCmd.Flags().Int64("seed", 0, "The seed for --sample random, the same seed keeps the same results. Defaults to a seed from the current time, which is printed")
+	trimCmd.Flags().StringSliceP("redirect", "r", []string{}, "Regex to filter redirect URLs. Prefix with ! to negate")
+	trimCmd.Flags().StringSliceP("url", "u", []string{}, "Regex to filter URLs. Prefix with ! to negate")
+	trimCmd.Flags().StringSlice("against", []string{}, "Remove the results already in these files or globs e.g. 'old/*.json'. The file being trimmed and the files trimmed after it are ignored")
+	trimCmd.Flags().String("against-key", "url", fmt.Sprintf("The key results are compared by for --against (%s)", strings.Join(gocdp.KeyNames(), "|")))
+	trimCmd.RegisterFlagCompletionFunc("against-key", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return gocdp.KeyNames(), cobra.ShellCompDirectiveDefault
+	})
+	trimCmd.Flags().Bool("literal", false, "Match the --url and --redirect filters as plain text instead of regexes")
+	trimCmd.Flags().BoolP("ignore-case", "i", false, "Match the --url and --redirect filters case-insensitively")
+	trimCmd.Flags().StringSliceP("content-type", "c", []string{}, "Filter content types. Prefix with ! to negate")
+	trimCmd.Flags().StringSliceP("status", "s", []string{}, "Filter status codes e.g. 404, 500-599. Prefix with ! to negate")
+	trimCmd.Flags().StringSliceP("length", "l", []string{}, "Filter content lengths e.g. 0, 1200-1300, 4242~2%, 4242~10. Prefix with ! to negate")
+	trimCmd.Flags().StringSliceP("words", "w", []string{}, "Filter number of words, same syntax as --length. Prefix with ! to negate")
+	trimCmd.Flags().StringSlice("lines", []string{}, "Filter number of lines, same syntax as --length. Prefix with ! to negate")
+	trimCmd.Flags().StringSlice("duration", []string{}, "Filter response durations e.g. 2s-, 100ms-500ms, 1s~10%. Prefix with ! to negate")
+	trimCmd.Flags().StringArrayP("query", "q", []string{}, "expression to filter results, the same as the root command's query")
 	trimCmd.Flags().StringP("operator", "o", "or", "The filter operator. Either of: and, or")
+	trimCmd.Flags().Bool("auto", false, "Remove clusters of results sharing the same --auto-by key e.g. wildcard or soft 404 responses")
+	trimCmd.Flags().String("auto-by", "status-length", fmt.Sprintf("The key results are clustered by for --auto (%s)", strings.Join(gocdp.KeyNames(), "|")))
+	trimCmd.Flags().Int("auto-count", 50, "The number of results a cluster needs to be removed by --auto. 0 to disable")
+	trimCmd.Flags().Float64("auto-share", 0, "The percentage of the results left in a file after the other filters a cluster needs to be removed by --auto e.g. 25. 0 to disable")
+	trimCmd.Flags().Int("auto-samples", 1, "The number of results kept from each cluster removed by --auto")
+	trimCmd.RegisterFlagCompletionFunc("auto-by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return gocdp.KeyNames(), cobra.ShellCompDirectiveDefault
+	})
+	trimCmd.Flags().BoolP("keep", "k", false, "Keep only the results matching the filters instead of removing them")
+	trimCmd.Flags().Bool("backup", false, "Keep a backup of each overwritten file. An existing backup is kept, so it is always the file before the first trim")
+	trimCmd.Flags().String("backup-suffix", ".orig", "The suffix added to the backup files")
+	trimCmd.Flags().String("output", "", "Write the trimmed files to this directory instead of overwriting them. Fails if two files have the same name")
+	trimCmd.Flags().Bool("dry-run", false, "Show how many results would be kept and removed by each filter without writing anything")
+	trimCmd.Flags().Bool("verify", false, "Show the number of verified results of each file. The trimmed output is always checked before it is written, unless --no-verify")
+	trimCmd.Flags().Bool("no-verify", false, "Write the trimmed output without checking it can be parsed and contains exactly the kept results. Not with --all-or-nothing")
+	trimCmd.Flags().Bool("all-or-nothing", false, "Trim and verify every file before writing any of them, restoring the written files if any write fails")
+	trimCmd.Flags().Bool("remove-responses", false, "Delete the stored response files of the removed results e.g. from ffuf -od. Not with --backup, --output or --suffix, as the kept originals still use them")
+	trimCmd.Flags().String("move-responses", "", "Move the stored response files of the removed results to this directory, keeping their path relative to the trimmed file, instead of deleting them. Never overwrites. Not with --backup, --output or --suffix")
+	trimCmd.Flags().Bool("diff", false, "Show the unified diff between the original and trimmed output")
+	trimCmd.Flags().String("suffix", "", "Write the trimmed files next to the originals with this suffix added before the extension e.g. .trimmed")
 }
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NoF0rte/gocdp"
+)
+
+func TestFilterOptionsNegation(t *testing.T) {
+	input, err := gocdp.RenderFfufJSON(gocdp.CDResults{
+		{Url: "http://t/api/a", Status: 200},
+		{Url: "http://t/api/b", Status: 403},
+		{Url: "http://t/c", Status: 200},
+	}, gocdp.ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		values   []string
+		expected []string
+	}{
+		{[]string{"/api/"}, []string{"http://t/c"}},
+		{[]string{"!/api/"}, []string{"http://t/api/a", "http://t/api/b"}},
+		{[]string{"/b$", "!/api/"}, []string{"http://t/api/a"}},
+	}
+
+	for _, test := range tests {
+		opts := filterOptions(test.values, gocdp.WithFilterURL)
+
+		output, err := gocdp.SmartTrim(strings.NewReader(input), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results, err := gocdp.SmartParse(strings.NewReader(output))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		urls := []string{}
+		for _, result := range results {
+			urls = append(urls, result.Url)
+		}
+		if !reflect.DeepEqual(urls, test.expected) {
+			t.Errorf("%v: expected %v, got %v", test.values, test.expected, urls)
+		}
+	}
+}
+
+func TestRangeFilterOptionsInvalid(t *testing.T) {
+	for _, values := range [][]string{{"abc"}, {"!500-400"}, {"200", "!"}} {
+		if _, err := rangeFilterOptions(values, gocdp.WithFilterStatusRange); err == nil {
+			t.Errorf("%v: expected an error", values)
+		}
+	}
+}
+
+func TestTrimQuery(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ffuf.json")
+	output, err := gocdp.RenderFfufJSON(gocdp.CDResults{
+		{Url: "http://t/a", Status: 200, ContentLength: 10},
+		{Url: "http://t/b", Status: 404, ContentLength: 10},
+		{Url: "http://t/c", Status: 200, ContentLength: 5000},
+	}, gocdp.ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(output), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each -q removes the results it matches
+	rootCmd.SetArgs([]string{"trim", file, "-q", "status == 404", "-q", "length > 1000"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := gocdp.SmartParseFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Url != "http://t/a" {
+		t.Errorf("expected only http://t/a to be kept, got %v", results)
+	}
+}
@@ -1,7 +1,7 @@
 package gocdp
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -34,13 +34,17 @@ func (parser DirbParser) Parse(input string) (CDResults, error) {
 		match := dirbResultRegex.FindStringSubmatch(line)
 		if len(match) == 0 {
 			match = dirbDirRegex.FindStringSubmatch(line)
+			if len(match) == 0 {
+				continue
+			}
+
 			result = CDResult{
 				Url:           match[1],
 				Status:        200, // Going to assume that directory matches are 200
 				Redirect:      "",
 				ContentType:   "",
 				ContentLength: 0,
-				source:        line,
+				source:        newTextRecord(line, len(results)),
 			}
 		} else {
 			namedMatches := make(map[string]string)
@@ -58,9 +62,9 @@ func (parser DirbParser) Parse(input string) (CDResults, error) {
 				Status:        status,
 				ContentLength: length,
 				ContentType:   "",
-				source:        line,
 			}
 
+			source := line
 			if i+1 < len(lines) {
 				nextLine := lines[i+1]
 				matches := dirbRedirectRegex.FindStringSubmatch(nextLine)
@@ -70,9 +74,10 @@ func (parser DirbParser) Parse(input string) (CDResults, error) {
 					}
 
 					// Always add it to source
-					result.source = fmt.Sprintf("%s\n%s", result.source, nextLine)
+					source = fmt.Sprintf("%s\n%s", source, nextLine)
 				}
 			}
+			result.source = newTextRecord(source, len(results))
 		}
 
 		results = append(results, result)
@@ -89,18 +94,20 @@ func (p DirbParser) CanTransform() bool {
 	return true
 }
 
-func (p DirbParser) Transform(input string, filtered []interface{}) (string, error) {
+func (p DirbParser) Transform(input string, records []Record) (string, error) {
 	beforeRegex := regexp.MustCompile(`----\s*Scanning\s*URL:\s*[^ ]+\s*----`)
 	afterRegex := regexp.MustCompile(`-----------------\nEND_TIME:\s*`)
 
-	writer := bytes.NewBuffer(nil)
-
-	for _, l := range filtered {
-		writer.WriteString(fmt.Sprintln(l))
+	lines, err := writeTextRecords(records)
+	if err != nil {
+		return "", err
 	}
 
-	before := beforeRegex.FindStringIndex(input)[1]
-	after := afterRegex.FindStringIndex(input)[0]
+	before := beforeRegex.FindStringIndex(input)
+	after := afterRegex.FindStringIndex(input)
+	if before == nil || after == nil || before[1] > after[0] {
+		return "", errors.New("could not find the dirb results section")
+	}
 
-	return fmt.Sprintf("%s\n%s\n%s", input[0:before], writer.String(), input[after:]), nil
+	return fmt.Sprintf("%s\n%s\n%s", input[0:before[1]], lines, input[after[0]:]), nil
 }
@@ -3,9 +3,12 @@ package gocdp
 import (
 	"bufio"
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
@@ -17,7 +20,7 @@ import (
 var (
 	dirSearchPlainRegex *regexp.Regexp = regexp.MustCompile(`(?m)^(?P<status>[0-9]+)\s*(?P<length>[0-9]+)(?P<units>[^ ]+)\s*(?P<url>[^ ]+)(?:\s*->\s*REDIRECTS TO:\s*(?P<redirect>[^ ]+))?$`)
 	dirSearchCSVRegex   *regexp.Regexp = regexp.MustCompile(`(?m)^URL,Status,Size,Content Type,Redirection$`)
-	dirSearchMDRegex    *regexp.Regexp = regexp.MustCompile(`(?m)^(?P<url>[^ ]+)\s*\|\s*(?P<status>[0-9]+)\s*\|\s*(?P<length>[0-9]+)\s*\|\s*(?P<content_type>[^ ]+)\s*\|\s*(?P<redirect>[^ ]+)?$`)
+	dirSearchMDRegex    *regexp.Regexp = regexp.MustCompile(`(?m)^(?P<url>[^ ]+)\s*\|\s*(?P<status>[0-9]+)\s*\|\s*(?P<length>[0-9]+)\s*\|\s*(?P<content_type>[^|]*?)\s*\|\s*(?P<redirect>[^ ]+)?$`)
 )
 
 type dirSearchJSONOutput struct {
@@ -28,6 +31,7 @@ type dirSearchJSONOutput struct {
 	Results []dirSearchResult `json:"results"`
 }
 type dirSearchXMLOutput struct {
+	XMLName xml.Name          `xml:"dirsearchscan"`
 	Args    string            `xml:"args,attr"`
 	Time    string            `xml:"time,attr"`
 	Results []dirSearchResult `xml:"target"`
@@ -38,7 +42,7 @@ type dirSearchResult struct {
 	ContentLength int    `json:"content-length" csv:"Size" xml:"contentLength"`
 	ContentType   string `json:"content-type" csv:"Content Type" xml:"contentType"`
 	Redirect      string `json:"redirect" csv:"Redirection" xml:"redirect,omitempty"`
-	raw           interface{}
+	raw           []byte
 }
 
 type _dirSearchResult dirSearchResult
@@ -48,12 +52,7 @@ func (r *dirSearchResult) UnmarshalJSON(bytes []byte) (err error) {
 
 	if err = json.Unmarshal(bytes, &foo); err == nil {
 		*r = dirSearchResult(foo)
-	}
-
-	m := orderedmap.New()
-
-	if err = json.Unmarshal(bytes, &m); err == nil {
-		r.raw = m
+		r.raw = append([]byte(nil), bytes...)
 	}
 
 	return err
@@ -124,7 +123,7 @@ func (DirSearchParser) parseJSON(input string) (CDResults, error) {
 			Redirect:      result.Redirect,
 			ContentType:   result.ContentType,
 			ContentLength: result.ContentLength,
-			source:        result.raw,
+			source:        newRecord(result.raw, RecordJSON, len(results)),
 		})
 	}
 	return results, nil
@@ -155,7 +154,7 @@ func (p DirSearchParser) parsePlain(input string) (CDResults, error) {
 			Status:        status,
 			ContentType:   "",
 			ContentLength: p.convertLength(length, namedMatches["units"]),
-			source:        line,
+			source:        newTextRecord(line, len(results)),
 		}
 
 		if result.IsRedirect() {
@@ -168,6 +167,28 @@ func (p DirSearchParser) parsePlain(input string) (CDResults, error) {
 	return results, nil
 }
 
+// csvRows returns each row of the CSV input, including the header, encoded on its own
+func (DirSearchParser) csvRows(input string) ([]string, error) {
+	records, err := csv.NewReader(strings.NewReader(input)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []string
+	for _, record := range records {
+		writer := bytes.NewBuffer(nil)
+		w := csv.NewWriter(writer)
+		w.Write(record)
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, strings.TrimSuffix(writer.String(), "\n"))
+	}
+	return rows, nil
+}
+
 func (p DirSearchParser) parseCSV(input string) (CDResults, error) {
 	var results CDResults
 	var rows []dirSearchResult
@@ -177,14 +198,23 @@ func (p DirSearchParser) parseCSV(input string) (CDResults, error) {
 		return nil, err
 	}
 
-	for _, result := range rows {
+	raws, err := p.csvRows(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raws) != len(rows)+1 {
+		return nil, fmt.Errorf("expected %d dirsearch CSV rows, found %d", len(rows)+1, len(raws))
+	}
+
+	for i, result := range rows {
 		results = append(results, CDResult{
 			Url:           result.URL,
 			Status:        result.Status,
 			Redirect:      result.Redirect,
 			ContentType:   result.ContentType,
 			ContentLength: result.ContentLength,
-			source:        result,
+			source:        newRecord([]byte(raws[i+1]), RecordCSV, len(results)),
 		})
 	}
 
@@ -192,21 +222,37 @@ func (p DirSearchParser) parseCSV(input string) (CDResults, error) {
 }
 
 func (DirSearchParser) parseXML(input string) (CDResults, error) {
-	var output dirSearchXMLOutput
-	err := xml.Unmarshal([]byte(input), &output)
-	if err != nil {
-		return nil, err
-	}
+	decoder := xml.NewDecoder(strings.NewReader(input))
 
 	var results CDResults
-	for _, result := range output.Results {
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok || element.Name.Local != "target" {
+			continue
+		}
+
+		var result dirSearchResult
+		err = decoder.DecodeElement(&result, &element)
+		if err != nil {
+			return nil, err
+		}
+
 		results = append(results, CDResult{
 			Url:           result.URL,
 			Status:        result.Status,
 			Redirect:      result.Redirect,
 			ContentType:   result.ContentType,
 			ContentLength: result.ContentLength,
-			source:        result,
+			source:        newRecord([]byte(input[start:decoder.InputOffset()]), RecordXML, len(results)),
 		})
 	}
 	return results, nil
@@ -235,9 +281,9 @@ func (p DirSearchParser) parseMD(input string) (CDResults, error) {
 		result := CDResult{
 			Url:           namedMatches["url"],
 			Status:        status,
-			ContentType:   namedMatches["content_type"],
+			ContentType:   strings.TrimSpace(namedMatches["content_type"]),
 			ContentLength: length,
-			source:        line,
+			source:        newTextRecord(line, len(results)),
 		}
 
 		if result.IsRedirect() {
@@ -276,14 +322,19 @@ func (p DirSearchParser) CanTransform() bool {
 	return true
 }
 
-func (p DirSearchParser) transformJSON(input string, filtered []interface{}) (string, error) {
+func (p DirSearchParser) transformJSON(input string, records []Record) (string, error) {
+	raws, err := jsonRecords(records)
+	if err != nil {
+		return "", err
+	}
+
 	output := orderedmap.New()
-	err := json.Unmarshal([]byte(input), output)
+	err = json.Unmarshal([]byte(input), output)
 	if err != nil {
 		return "", err
 	}
 
-	output.Set("results", filtered)
+	output.Set("results", raws)
 
 	bytes, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
@@ -293,45 +344,62 @@ func (p DirSearchParser) transformJSON(input string, filtered []interface{}) (st
 	return string(bytes), nil
 }
 
-func (p DirSearchParser) transformPlain(input string, filtered []interface{}) (string, error) {
+func (p DirSearchParser) transformPlain(input string, records []Record) (string, error) {
 	beforeRegex := regexp.MustCompile(`(?m)^#\s*Dirsearch started .*$`)
 
-	writer := bytes.NewBuffer(nil)
-
-	for _, l := range filtered {
-		writer.WriteString(fmt.Sprintln(l))
+	lines, err := writeTextRecords(records)
+	if err != nil {
+		return "", err
 	}
 
-	before := beforeRegex.FindStringIndex(input)[1]
+	before := beforeRegex.FindStringIndex(input)
+	if before == nil {
+		return "", errors.New("could not find the dirsearch header")
+	}
 
-	return fmt.Sprintf("%s\n\n%s", input[0:before], writer.String()), nil
+	return fmt.Sprintf("%s\n\n%s", input[0:before[1]], lines), nil
 }
 
-func (p DirSearchParser) transformCSV(input string, filtered []interface{}) (string, error) {
-	// gocsv cannot marshal a slice of interfaces, they must be structs
-	var results []dirSearchResult
-	for _, r := range filtered {
-		results = append(results, r.(dirSearchResult))
+func (p DirSearchParser) transformCSV(input string, records []Record) (string, error) {
+	err := checkRecords(records, RecordCSV)
+	if err != nil {
+		return "", err
 	}
 
-	rows, err := gocsv.MarshalString(&results)
+	rows, err := p.csvRows(input)
 	if err != nil {
 		return "", err
 	}
 
-	return rows, nil
+	writer := bytes.NewBuffer(nil)
+	writer.WriteString(fmt.Sprintln(rows[0]))
+	for _, record := range records {
+		writer.WriteString(fmt.Sprintln(record.String()))
+	}
+
+	return writer.String(), nil
 }
 
-func (p DirSearchParser) transformXML(input string, filtered []interface{}) (string, error) {
+func (p DirSearchParser) transformXML(input string, records []Record) (string, error) {
 	type dirsearchscan dirSearchXMLOutput
 
+	err := checkRecords(records, RecordXML)
+	if err != nil {
+		return "", err
+	}
+
 	var results []dirSearchResult
-	for _, r := range filtered {
-		results = append(results, r.(dirSearchResult))
+	for _, record := range records {
+		var result dirSearchResult
+		err = xml.Unmarshal(record.raw, &result)
+		if err != nil {
+			return "", err
+		}
+		results = append(results, result)
 	}
 
 	var output dirsearchscan
-	err := xml.Unmarshal([]byte(input), &output)
+	err = xml.Unmarshal([]byte(input), &output)
 	if err != nil {
 		return "", err
 	}
@@ -346,31 +414,33 @@ func (p DirSearchParser) transformXML(input string, filtered []interface{}) (str
 	return fmt.Sprintf("%s\n%s\n", `<?xml version="1.0" ?>`, string(bytes)), nil
 }
 
-func (p DirSearchParser) transformMD(input string, filtered []interface{}) (string, error) {
+func (p DirSearchParser) transformMD(input string, records []Record) (string, error) {
 	beforeRegex := regexp.MustCompile(`(?m)([\-]+\|?)+$`)
 
-	writer := bytes.NewBuffer(nil)
-
-	for _, l := range filtered {
-		writer.WriteString(fmt.Sprintln(l))
+	lines, err := writeTextRecords(records)
+	if err != nil {
+		return "", err
 	}
 
-	before := beforeRegex.FindStringIndex(input)[1]
+	before := beforeRegex.FindStringIndex(input)
+	if before == nil {
+		return "", errors.New("could not find the dirsearch markdown table header")
+	}
 
-	return fmt.Sprintf("%s\n%s", input[0:before], writer.String()), nil
+	return fmt.Sprintf("%s\n%s", input[0:before[1]], lines), nil
 }
 
-func (p DirSearchParser) Transform(input string, filtered []interface{}) (string, error) {
+func (p DirSearchParser) Transform(input string, records []Record) (string, error) {
 	if p.isJSONResult(input) {
-		return p.transformJSON(input, filtered)
+		return p.transformJSON(input, records)
 	} else if p.isPlainResult(input) {
-		return p.transformPlain(input, filtered)
+		return p.transformPlain(input, records)
 	} else if p.isCSVResult(input) {
-		return p.transformCSV(input, filtered)
+		return p.transformCSV(input, records)
 	} else if p.isXMLResult(input) {
-		return p.transformXML(input, filtered)
+		return p.transformXML(input, records)
 	} else if p.isMDResult(input) {
-		return p.transformMD(input, filtered)
+		return p.transformMD(input, records)
 	}
 
 	return "", nil
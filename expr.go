@@ -0,0 +1,939 @@
+package gocdp
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression is a compiled filter expression which is evaluated against each result
+// e.g. 'status in 200..299 and url =~ "/admin" and not redirect'.
+//
+// Fields are compared with ==, !=, <, <=, >, >=, matched with =~ and !~ (regex), glob (* and ?) and contains,
+// and checked with in against ranges e.g. 200..299, 500.., ..1s, values with a tolerance e.g. 4242~10, 4242~2%, 1s~100ms
+// or lists e.g. [200, 301, 500..599] or ["text/html", "text/plain"].
+// A field on its own is true when it is set e.g. 'redirect' or 'not params'.
+// Expressions are combined with and, or, not (or &&, ||, !) and grouped with parentheses
+type Expression struct {
+	expr  string
+	match func(CDResult) bool
+}
+
+// ExpressionError is a syntax or type error in an expression. Pos is the byte offset of the error in the expression
+type ExpressionError struct {
+	Expr string
+	Pos  int
+	Msg  string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("invalid expression at position %d: %s\n  %s\n  %s^", e.Pos+1, e.Msg, e.Expr, strings.Repeat(" ", e.Pos))
+}
+
+type valueKind int
+
+const (
+	kindBool valueKind = iota
+	kindInt
+	kindDuration
+	kindString
+	kindList
+	// kindAny is a string or a list, only known when evaluated e.g. the Extra fields
+	kindAny
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindBool:
+		return "bool"
+	case kindInt:
+		return "number"
+	case kindDuration:
+		return "duration"
+	case kindString:
+		return "string"
+	case kindList:
+		return "list"
+	}
+	return "extra"
+}
+
+type exprField struct {
+	kind  valueKind
+	value func(CDResult) interface{}
+}
+
+var exprFields = map[string]exprField{
+	"url":            {kindString, func(r CDResult) interface{} { return r.Url }},
+	"host":           {kindString, func(r CDResult) interface{} { return KeyHost(r) }},
+	"path":           {kindString, func(r CDResult) interface{} { return urlPath(r.Url) }},
+	"status":         {kindInt, func(r CDResult) interface{} { return r.Status }},
+	"redirect":       {kindString, func(r CDResult) interface{} { return r.Redirect }},
+	"content_type":   {kindString, func(r CDResult) interface{} { return r.ContentType }},
+	"length":         {kindInt, func(r CDResult) interface{} { return r.ContentLength }},
+	"words":          {kindInt, func(r CDResult) interface{} { return r.Words }},
+	"lines":          {kindInt, func(r CDResult) interface{} { return r.Lines }},
+	"duration":       {kindDuration, func(r CDResult) interface{} { return r.Duration }},
+	"params":         {kindList, func(r CDResult) interface{} { return r.Params }},
+	"param_location": {kindString, func(r CDResult) interface{} { return r.ParamLocation }},
+	"response_file":  {kindString, func(r CDResult) interface{} { return r.ResponseFile }},
+	"raw":            {kindString, func(r CDResult) interface{} { return r.Raw() }},
+	"probed":         {kindBool, func(r CDResult) interface{} { return r.IsProbed() }},
+	"success":        {kindBool, func(r CDResult) interface{} { return r.IsSuccess() }},
+	"error":          {kindBool, func(r CDResult) interface{} { return r.IsError() }},
+	"auth_error":     {kindBool, func(r CDResult) interface{} { return r.IsAuthError() }},
+	"rate_limit":     {kindBool, func(r CDResult) interface{} { return r.IsRateLimit() }},
+}
+
+// ExpressionFields returns the names of the fields which can be used in expressions, besides extra.<name> for the Extra fields
+func ExpressionFields() []string {
+	var names []string
+	for name := range exprFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// urlPath returns the path of the URL
+func urlPath(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	return parsed.Path
+}
+
+// extraValue returns the Extra field as a string, or a list of strings for lists
+func extraValue(r CDResult, name string) interface{} {
+	value, ok := r.Extra[name]
+	if !ok || value == nil {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case []string:
+		return v
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	}
+	return fmt.Sprint(value)
+}
+
+// templateQueryRegex matches the golang template queries used before expressions e.g. '.IsSuccess' or '{{.IsSuccess}}'
+var templateQueryRegex = regexp.MustCompile(`(?:^|[\s(])(\{\{|\.[A-Z])|(\{\{)`)
+
+// ParseExpression compiles the expression, see Expression for the syntax. The golang template queries
+// used before expressions e.g. '.IsSuccess' are no longer supported and return an error pointing to the new syntax
+func ParseExpression(expr string) (*Expression, error) {
+	e, err := parseExpression(expr)
+	if err != nil {
+		if loc := templateQueryRegex.FindStringSubmatchIndex(expr); loc != nil {
+			pos := loc[2]
+			if pos == -1 {
+				pos = loc[4]
+			}
+			return nil, &ExpressionError{
+				Expr: expr,
+				Pos:  pos,
+				Msg:  "golang template queries are no longer supported, use an expression instead e.g. 'success' for '.IsSuccess' or 'status in 300..399' for '.StatusIn \"300-399\"'",
+			}
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+func parseExpression(expr string) (*Expression, error) {
+	tokens, err := lexExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{
+		expr:   expr,
+		tokens: tokens,
+	}
+
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t.pos, "unexpected '%s'", t.text)
+	}
+
+	return &Expression{
+		expr:  expr,
+		match: match,
+	}, nil
+}
+
+func (e *Expression) String() string {
+	return e.expr
+}
+
+// Match returns whether the expression is true for the result
+func (e *Expression) Match(result CDResult) bool {
+	return e.match(result)
+}
+
+// Where returns the results matching the expression, see Expression for the syntax
+func (results CDResults) Where(expr string) (CDResults, error) {
+	e, err := ParseExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := CDResults{}
+	for _, result := range results {
+		if e.Match(result) {
+			matches = append(matches, result)
+		}
+	}
+	return matches, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokDuration
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+
+	str string
+	num int64
+	// decimal is whether the number has a decimal point, which is only allowed in tolerances e.g. 4242~0.5%
+	decimal bool
+}
+
+var exprOps = []string{"==", "!=", "=~", "!~", "<=", ">=", "&&", "||", "..", "<", ">", "=", "!", "(", ")", "[", "]", ",", "~", "%"}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// durationUnits are the units of time.ParseDuration, the longer units first
+var durationUnits = []string{"ns", "us", "µs", "μs", "ms", "s", "m", "h"}
+
+// numberEnd returns the end of the digits starting at i, including a decimal part e.g. 1.5 but not the range 1..5
+func numberEnd(expr string, i int) int {
+	j := i
+	for j < len(expr) && isDigit(expr[j]) {
+		j++
+	}
+
+	if j+1 < len(expr) && expr[j] == '.' && isDigit(expr[j+1]) {
+		j++
+		for j < len(expr) && isDigit(expr[j]) {
+			j++
+		}
+	}
+	return j
+}
+
+// durationEnd returns the end of the duration whose first number ends at i, reading every number and unit pair
+// as time.ParseDuration does e.g. 1h30m. Returns -1 when the number has no unit or the duration is not followed
+// by a word boundary, so 200..299and is the number 299 followed by and
+func durationEnd(expr string, i int) int {
+	end := -1
+	for {
+		unit := ""
+		for _, u := range durationUnits {
+			if strings.HasPrefix(expr[i:], u) {
+				unit = u
+				break
+			}
+		}
+
+		if unit == "" {
+			break
+		}
+		i += len(unit)
+		end = i
+
+		next := numberEnd(expr, i)
+		if next == i {
+			break
+		}
+		i = next
+	}
+
+	if end == -1 || (end < len(expr) && (isIdentStart(expr[end]) || isDigit(expr[end]))) {
+		return -1
+	}
+	return end
+}
+
+func lexExpression(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			// Only the quote and backslash are escaped so regexes such as "\d+" need no double escaping
+			var str strings.Builder
+			j := i + 1
+			for ; j < len(expr) && expr[j] != c; j++ {
+				if expr[j] == '\\' && j+1 < len(expr) && (expr[j+1] == c || expr[j+1] == '\\') {
+					j++
+				}
+				str.WriteByte(expr[j])
+			}
+
+			if j >= len(expr) {
+				return nil, &ExpressionError{Expr: expr, Pos: i, Msg: "unterminated string"}
+			}
+
+			tokens = append(tokens, token{kind: tokString, text: expr[i : j+1], pos: i, str: str.String()})
+			i = j + 1
+		case isDigit(c) || (c == '-' && i+1 < len(expr) && isDigit(expr[i+1])):
+			j := numberEnd(expr, i+1)
+			number := expr[i:j]
+
+			if end := durationEnd(expr, j); end != -1 {
+				d, err := time.ParseDuration(expr[i:end])
+				if err != nil {
+					return nil, &ExpressionError{Expr: expr, Pos: i, Msg: fmt.Sprintf("invalid duration '%s'", expr[i:end])}
+				}
+				tokens = append(tokens, token{kind: tokDuration, text: expr[i:end], pos: i, num: int64(d)})
+				j = end
+			} else if strings.Contains(number, ".") {
+				f, err := strconv.ParseFloat(number, 64)
+				if err != nil {
+					return nil, &ExpressionError{Expr: expr, Pos: i, Msg: fmt.Sprintf("invalid number '%s'", number)}
+				}
+				tokens = append(tokens, token{kind: tokNumber, text: number, pos: i, num: int64(f), decimal: true})
+			} else {
+				n, err := strconv.ParseInt(number, 10, 64)
+				if err != nil {
+					return nil, &ExpressionError{Expr: expr, Pos: i, Msg: fmt.Sprintf("invalid number '%s'", number)}
+				}
+				tokens = append(tokens, token{kind: tokNumber, text: number, pos: i, num: n})
+			}
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(expr) && (isIdentStart(expr[j]) || isDigit(expr[j]) || expr[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: expr[i:j], pos: i})
+			i = j
+		default:
+			found := false
+			for _, op := range exprOps {
+				if strings.HasPrefix(expr[i:], op) {
+					tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, &ExpressionError{Expr: expr, Pos: i, Msg: fmt.Sprintf("unexpected character '%c'", c)}
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, text: "end of expression", pos: len(expr)}), nil
+}
+
+type exprParser struct {
+	expr   string
+	tokens []token
+	pos    int
+}
+
+// operand is a field or a literal
+type operand struct {
+	kind    valueKind
+	pos     int
+	text    string
+	literal bool
+	value   func(CDResult) interface{}
+}
+
+func (p *exprParser) errorf(pos int, format string, args ...interface{}) error {
+	return &ExpressionError{Expr: p.expr, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword returns whether the token is the keyword, or one of the operators given as alternatives
+func (t token) isKeyword(keyword string, ops ...string) bool {
+	if t.kind == tokIdent {
+		return strings.ToLower(t.text) == keyword
+	}
+
+	if t.kind == tokOp {
+		for _, op := range ops {
+			if t.text == op {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t token) isOp(op string) bool {
+	return t.kind == tokOp && t.text == op
+}
+
+func (p *exprParser) parseOr() (func(CDResult) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().isKeyword("or", "||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(r CDResult) bool {
+			return l(r) || right(r)
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (func(CDResult) bool, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().isKeyword("and", "&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(r CDResult) bool {
+			return l(r) && right(r)
+		}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (func(CDResult) bool, error) {
+	if p.peek().isKeyword("not", "!") {
+		p.next()
+		match, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(r CDResult) bool {
+			return !match(r)
+		}, nil
+	}
+
+	if p.peek().isOp("(") {
+		p.next()
+		match, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if t := p.next(); !t.isOp(")") {
+			return nil, p.errorf(t.pos, "expected ')', found '%s'", t.text)
+		}
+		return match, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseOperand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		str := t.str
+		return operand{kind: kindString, pos: t.pos, text: t.text, literal: true, value: func(CDResult) interface{} { return str }}, nil
+	case tokNumber:
+		if t.decimal {
+			return operand{}, p.errorf(t.pos, "expected a whole number, found '%s'", t.text)
+		}
+		n := int(t.num)
+		return operand{kind: kindInt, pos: t.pos, text: t.text, literal: true, value: func(CDResult) interface{} { return n }}, nil
+	case tokDuration:
+		d := time.Duration(t.num)
+		return operand{kind: kindDuration, pos: t.pos, text: t.text, literal: true, value: func(CDResult) interface{} { return d }}, nil
+	case tokIdent:
+		name := strings.ToLower(t.text)
+		switch name {
+		case "true", "false":
+			b := name == "true"
+			return operand{kind: kindBool, pos: t.pos, text: t.text, literal: true, value: func(CDResult) interface{} { return b }}, nil
+		case "and", "or", "not", "in", "contains", "glob":
+			return operand{}, p.errorf(t.pos, "expected a field or value, found '%s'", t.text)
+		}
+
+		if strings.HasPrefix(name, "extra.") {
+			extra := t.text[len("extra."):]
+			if extra == "" {
+				return operand{}, p.errorf(t.pos, "expected the name of the extra field e.g. extra.title")
+			}
+			return operand{kind: kindAny, pos: t.pos, text: t.text, value: func(r CDResult) interface{} { return extraValue(r, extra) }}, nil
+		}
+
+		field, ok := exprFields[name]
+		if !ok {
+			return operand{}, p.errorf(t.pos, "unknown field '%s', expected one of: %s, extra.<name>", t.text, strings.Join(ExpressionFields(), ", "))
+		}
+		return operand{kind: field.kind, pos: t.pos, text: t.text, value: field.value}, nil
+	}
+
+	return operand{}, p.errorf(t.pos, "expected a field or value, found '%s'", t.text)
+}
+
+func (p *exprParser) parseComparison() (func(CDResult) bool, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	switch {
+	case t.isOp("==") || t.isOp("=") || t.isOp("!="):
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		equal, err := p.equal(left, right, t)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.text == "!=" {
+			return func(r CDResult) bool { return !equal(r) }, nil
+		}
+		return equal, nil
+	case t.isOp("<") || t.isOp("<=") || t.isOp(">") || t.isOp(">="):
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return p.compare(left, right, t)
+	case t.isOp("=~") || t.isOp("!~") || t.isKeyword("glob") || t.isKeyword("contains"):
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		match, err := p.match(left, right, t)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.text == "!~" {
+			return func(r CDResult) bool { return !match(r) }, nil
+		}
+		return match, nil
+	case t.isKeyword("in"):
+		p.next()
+		return p.parseIn(left, t)
+	}
+
+	return truthy(left), nil
+}
+
+// truthy returns whether the operand is set e.g. a non empty string or a positive number
+func truthy(o operand) func(CDResult) bool {
+	return func(r CDResult) bool {
+		switch v := o.value(r).(type) {
+		case bool:
+			return v
+		case int:
+			return v > 0
+		case time.Duration:
+			return v > 0
+		case string:
+			return v != ""
+		case []string:
+			return len(v) != 0
+		}
+		return false
+	}
+}
+
+// texts returns the value of a string, list or extra operand as a list
+func texts(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	}
+	return nil
+}
+
+func isText(kind valueKind) bool {
+	return kind == kindString || kind == kindList || kind == kindAny
+}
+
+func isNumeric(kind valueKind) bool {
+	return kind == kindInt || kind == kindDuration
+}
+
+func numeric(v interface{}) int64 {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case time.Duration:
+		return int64(v)
+	}
+	return 0
+}
+
+func (p *exprParser) mismatch(left operand, right operand, op token) error {
+	if left.kind == kindDuration && right.kind == kindInt && right.literal {
+		return p.errorf(right.pos, "cannot use %s %s a number, use a duration e.g. 1s or 500ms", left.text, op.text)
+	}
+	return p.errorf(op.pos, "cannot use %s (%s) %s %s (%s)", left.text, left.kind, op.text, right.text, right.kind)
+}
+
+func (p *exprParser) equal(left operand, right operand, op token) (func(CDResult) bool, error) {
+	switch {
+	case isText(left.kind) && isText(right.kind) && (left.kind != kindList || right.kind != kindList):
+		return func(r CDResult) bool {
+			for _, l := range texts(left.value(r)) {
+				for _, rt := range texts(right.value(r)) {
+					if l == rt {
+						return true
+					}
+				}
+			}
+			return false
+		}, nil
+	case left.kind == right.kind && isNumeric(left.kind):
+		return func(r CDResult) bool {
+			return numeric(left.value(r)) == numeric(right.value(r))
+		}, nil
+	case left.kind == kindBool && right.kind == kindBool:
+		return func(r CDResult) bool {
+			return left.value(r).(bool) == right.value(r).(bool)
+		}, nil
+	}
+	return nil, p.mismatch(left, right, op)
+}
+
+func (p *exprParser) compare(left operand, right operand, op token) (func(CDResult) bool, error) {
+	if left.kind != right.kind || !isNumeric(left.kind) {
+		return nil, p.mismatch(left, right, op)
+	}
+
+	return func(r CDResult) bool {
+		l := numeric(left.value(r))
+		rt := numeric(right.value(r))
+		switch op.text {
+		case "<":
+			return l < rt
+		case "<=":
+			return l <= rt
+		case ">":
+			return l > rt
+		}
+		return l >= rt
+	}, nil
+}
+
+// globRegex converts the glob to a regex, * matches any text and ? any single character
+func globRegex(glob string) string {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, c := range glob {
+		switch c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return expr.String()
+}
+
+func (p *exprParser) match(left operand, right operand, op token) (func(CDResult) bool, error) {
+	if !isText(left.kind) {
+		return nil, p.errorf(left.pos, "cannot use %s with %s, which is a %s", op.text, left.text, left.kind)
+	}
+
+	if right.kind != kindString {
+		return nil, p.errorf(right.pos, "expected a string after %s, found %s (%s)", op.text, right.text, right.kind)
+	}
+
+	if op.isKeyword("contains") {
+		return func(r CDResult) bool {
+			value := left.value(r)
+			substr := right.value(r).(string)
+			if s, ok := value.(string); ok {
+				return strings.Contains(s, substr)
+			}
+
+			// Lists contain the element
+			for _, item := range texts(value) {
+				if item == substr {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	if !right.literal {
+		return nil, p.errorf(right.pos, "expected a string literal after %s, found %s", op.text, right.text)
+	}
+
+	pattern := right.value(CDResult{}).(string)
+	if op.isKeyword("glob") {
+		pattern = globRegex(pattern)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, p.errorf(right.pos, "invalid regex %s: %v", right.text, err)
+	}
+
+	return func(r CDResult) bool {
+		for _, s := range texts(left.value(r)) {
+			if re.MatchString(s) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// numericRange is an inclusive range of numbers or durations, either end may be open
+type numericRange struct {
+	min    int64
+	max    int64
+	hasMin bool
+	hasMax bool
+}
+
+func (r numericRange) contains(n int64) bool {
+	return (!r.hasMin || n >= r.min) && (!r.hasMax || n <= r.max)
+}
+
+// parseRange parses a number or duration, a range of them e.g. 200..299, 500.. or ..1s,
+// or a value with a tolerance e.g. 4242~10, 4242~2% or 1s~100ms, the same as ParseIntRange and ParseDurationRange
+func (p *exprParser) parseRange(kind valueKind) (numericRange, error) {
+	valueToken := tokNumber
+	if kind == kindDuration {
+		valueToken = tokDuration
+	}
+
+	var r numericRange
+	t := p.peek()
+	if t.kind == valueToken {
+		p.next()
+		if t.decimal {
+			return r, p.errorf(t.pos, "expected a whole number, found '%s'", t.text)
+		}
+
+		r.min, r.hasMin = t.num, true
+		if p.peek().isOp("~") {
+			return p.parseTolerance(kind, t)
+		}
+
+		if !p.peek().isOp("..") {
+			r.max, r.hasMax = t.num, true
+			return r, nil
+		}
+	} else if !t.isOp("..") {
+		if kind == kindDuration && t.kind == tokNumber {
+			return r, p.errorf(t.pos, "expected a duration e.g. 1s or 500ms, found '%s'", t.text)
+		}
+		return r, p.errorf(t.pos, "expected a %s or a range, found '%s'", kind, t.text)
+	}
+
+	// The ..
+	p.next()
+	if t := p.peek(); t.kind == valueToken {
+		p.next()
+		r.max, r.hasMax = t.num, true
+	}
+
+	if !r.hasMin && !r.hasMax {
+		return r, p.errorf(t.pos, "expected a %s before or after ..", kind)
+	}
+
+	if r.hasMin && r.hasMax && r.min > r.max {
+		return r, p.errorf(t.pos, "invalid range, the start is after the end")
+	}
+	return r, nil
+}
+
+// parseTolerance parses the tolerance after the value e.g. the ~2% of 4242~2%, returning the range around the value.
+// Numbers are absolute or relative with %, durations are absolute e.g. 1s~100ms or relative e.g. 1s~10%
+func (p *exprParser) parseTolerance(kind valueKind, value token) (numericRange, error) {
+	// The ~
+	p.next()
+
+	t := p.next()
+	var tolerance float64
+	switch {
+	case t.kind == tokNumber && p.peek().isOp("%"):
+		p.next()
+		percent, err := parseTolerance(t.text+"%", float64(value.num))
+		if err != nil {
+			return numericRange{}, p.errorf(t.pos, "invalid tolerance '%s%%', expected a positive percentage", t.text)
+		}
+		tolerance = percent
+	case t.kind == tokNumber && kind == kindInt:
+		absolute, err := parseTolerance(t.text, float64(value.num))
+		if err != nil {
+			return numericRange{}, p.errorf(t.pos, "invalid tolerance '%s', expected a positive number", t.text)
+		}
+		tolerance = absolute
+	case t.kind == tokDuration && kind == kindDuration:
+		if t.num < 0 {
+			return numericRange{}, p.errorf(t.pos, "invalid tolerance '%s', expected a positive duration", t.text)
+		}
+		tolerance = float64(t.num)
+	case kind == kindDuration:
+		return numericRange{}, p.errorf(t.pos, "expected a duration e.g. 100ms or a percentage e.g. 10%% after ~, found '%s'", t.text)
+	default:
+		return numericRange{}, p.errorf(t.pos, "expected a number e.g. 10 or a percentage e.g. 2%% after ~, found '%s'", t.text)
+	}
+
+	delta := int64(math.Round(tolerance))
+	r := numericRange{min: value.num - delta, max: value.num + delta, hasMin: true, hasMax: true}
+	// The range is inverted when it overflows
+	if tolerance >= math.MaxInt64 || r.min > r.max {
+		return numericRange{}, p.errorf(t.pos, "the tolerance '%s' is too large", t.text)
+	}
+	return r, nil
+}
+
+func (p *exprParser) parseIn(left operand, op token) (func(CDResult) bool, error) {
+	if isNumeric(left.kind) {
+		var ranges []numericRange
+		if p.peek().isOp("[") {
+			p.next()
+			for {
+				r, err := p.parseRange(left.kind)
+				if err != nil {
+					return nil, err
+				}
+				ranges = append(ranges, r)
+
+				if t := p.next(); t.isOp("]") {
+					break
+				} else if !t.isOp(",") {
+					return nil, p.errorf(t.pos, "expected ',' or ']', found '%s'", t.text)
+				}
+			}
+		} else {
+			r, err := p.parseRange(left.kind)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, r)
+		}
+
+		return func(r CDResult) bool {
+			n := numeric(left.value(r))
+			for _, rng := range ranges {
+				if rng.contains(n) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	if !isText(left.kind) {
+		return nil, p.errorf(left.pos, "cannot use in with %s, which is a %s", left.text, left.kind)
+	}
+
+	// A list field e.g. "debug" in params
+	if !p.peek().isOp("[") {
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if right.kind != kindList && right.kind != kindAny {
+			return nil, p.errorf(right.pos, "expected a list e.g. [\"a\", \"b\"] or a list field such as params, found %s (%s)", right.text, right.kind)
+		}
+		return p.equal(left, right, op)
+	}
+
+	p.next()
+	set := make(map[string]bool)
+	for {
+		t := p.next()
+		if t.kind != tokString {
+			return nil, p.errorf(t.pos, "expected a string, found '%s'", t.text)
+		}
+		set[t.str] = true
+
+		if t := p.next(); t.isOp("]") {
+			break
+		} else if !t.isOp(",") {
+			return nil, p.errorf(t.pos, "expected ',' or ']', found '%s'", t.text)
+		}
+	}
+
+	return func(r CDResult) bool {
+		for _, s := range texts(left.value(r)) {
+			if set[s] {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,265 @@
+package gocdp
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var exprResults = CDResults{
+	{Url: "http://a.example.com/", Status: 200, ContentLength: 4242, ContentType: "text/html; charset=utf-8", Words: 100, Lines: 10, Duration: time.Second},
+	{Url: "http://a.example.com/admin", Status: 301, ContentLength: 0, Redirect: "http://a.example.com/admin/", Duration: 100 * time.Millisecond},
+	{Url: "http://a.example.com/api/users.json", Status: 403, ContentLength: 4300, ContentType: "application/json", Params: []string{"id", "debug"}, ParamLocation: "query"},
+	{Url: "http://b.example.com/login", Status: 429, ContentLength: 12, Extra: map[string]interface{}{"title": "Login", "tech": []interface{}{"Nginx", "PHP"}}},
+	{Url: "http://b.example.com/old", Status: StatusUnknown, ContentLength: LengthUnknown},
+}
+
+// exprPaths returns the paths of the results matching the expression
+func exprPaths(t *testing.T, expr string) []string {
+	t.Helper()
+
+	matches, err := exprResults.Where(expr)
+	if err != nil {
+		t.Fatalf("%s: %v", expr, err)
+	}
+
+	paths := []string{}
+	for _, result := range matches {
+		paths = append(paths, urlPath(result.Url))
+	}
+	return paths
+}
+
+func TestExpression(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		// Comparisons
+		{"status == 200", []string{"/"}},
+		{"status = 200", []string{"/"}},
+		{"status != 200", []string{"/admin", "/api/users.json", "/login", "/old"}},
+		{"status >= 400", []string{"/api/users.json", "/login"}},
+		{"length < 100 and probed", []string{"/admin", "/login"}},
+		{"status == -1", []string{"/old"}},
+		{"duration > 500ms", []string{"/"}},
+		{"duration <= 100ms and duration > 0s", []string{"/admin"}},
+		{"duration < 1h30m and duration > 0.5s", []string{"/"}},
+		{"status in 200..299and probed", []string{"/"}},
+		{`host == "b.example.com"`, []string{"/login", "/old"}},
+		{`'text/html; charset=utf-8' == content_type`, []string{"/"}},
+
+		// Precedence
+		{"not success or redirect", []string{"/admin", "/api/users.json", "/login", "/old"}},
+		{"not (success or redirect)", []string{"/api/users.json", "/login", "/old"}},
+		{"success or status == 301 and length > 0", []string{"/"}},
+		{"(success or status == 301) and length >= 0", []string{"/", "/admin"}},
+		{"!success && !probed", []string{"/old"}},
+		{"not not success", []string{"/"}},
+		{"error || auth_error", []string{"/api/users.json", "/login"}},
+
+		// Truthy fields
+		{"redirect", []string{"/admin"}},
+		{"params", []string{"/api/users.json"}},
+		{"not content_type", []string{"/admin", "/login", "/old"}},
+		{"rate_limit", []string{"/login"}},
+		{"success == true", []string{"/"}},
+
+		// in with ranges, tolerances and lists
+		{"status in 300..399", []string{"/admin"}},
+		{"status in 400..", []string{"/api/users.json", "/login"}},
+		{"status in ..299 and probed", []string{"/"}},
+		{"status in [200, 429, 500..599]", []string{"/", "/login"}},
+		{"length in 4242~2%", []string{"/", "/api/users.json"}},
+		{"length in 4242~10", []string{"/"}},
+		{"length in 4242~0.5%", []string{"/"}},
+		{"length in [12, 4300~1]", []string{"/api/users.json", "/login"}},
+		{"duration in 1s~10%", []string{"/"}},
+		{"duration in 150ms~50ms", []string{"/admin"}},
+		{"duration in ..200ms and probed", []string{"/admin", "/api/users.json", "/login"}},
+		{"not status in [400, 429, 401]", []string{"/", "/admin", "/api/users.json", "/old"}},
+		{`content_type in ["application/json", "text/plain"]`, []string{"/api/users.json"}},
+		{`"debug" in params`, []string{"/api/users.json"}},
+		{`"admin" in params`, []string{}},
+		{`"PHP" in extra.tech`, []string{"/login"}},
+
+		// Matching
+		{`url =~ "/api/.*\.json$"`, []string{"/api/users.json"}},
+		{`url !~ "example\.com/.+"`, []string{"/"}},
+		{`path glob "/a*"`, []string{"/admin", "/api/users.json"}},
+		{`path glob "/???"`, []string{"/old"}},
+		{`url contains "login"`, []string{"/login"}},
+		{`params contains "id"`, []string{"/api/users.json"}},
+		{`params contains "i"`, []string{}},
+		{`raw contains "x"`, []string{}},
+
+		// Extra fields
+		{"extra.title", []string{"/login"}},
+		{`extra.title == "Login"`, []string{"/login"}},
+		{`extra.tech contains "Nginx"`, []string{"/login"}},
+		{`extra.tech =~ "^PH"`, []string{"/login"}},
+		{"extra.missing", []string{}},
+		{"NOT Success", []string{"/admin", "/api/users.json", "/login", "/old"}},
+	}
+
+	for _, test := range tests {
+		paths := exprPaths(t, test.expr)
+		if !reflect.DeepEqual(paths, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.expr, test.expected, paths)
+		}
+	}
+}
+
+func TestLexExpressionNumbers(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{"1h30m", []string{"duration 1h30m"}},
+		{"2m30.5s", []string{"duration 2m30.5s"}},
+		{"-1h2m3s4ms5us6ns", []string{"duration -1h2m3s4ms5us6ns"}},
+		{"1.5µs", []string{"duration 1.5µs"}},
+		{"500ms)", []string{"duration 500ms", "op )"}},
+		{"200..299and", []string{"number 200", "op ..", "number 299", "ident and"}},
+		{"1s..2sor", []string{"duration 1s", "op ..", "number 2", "ident sor"}},
+		{"1h30", []string{"number 1", "ident h30"}},
+		{"1.5", []string{"number 1.5"}},
+		{"4242~2%", []string{"number 4242", "op ~", "number 2", "op %"}},
+	}
+
+	kinds := map[tokenKind]string{tokNumber: "number", tokDuration: "duration", tokOp: "op", tokIdent: "ident", tokString: "string"}
+	for _, test := range tests {
+		tokens, err := lexExpression(test.expr)
+		if err != nil {
+			t.Errorf("%s: %v", test.expr, err)
+			continue
+		}
+
+		actual := []string{}
+		for _, tok := range tokens[:len(tokens)-1] {
+			actual = append(actual, kinds[tok.kind]+" "+tok.text)
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.expr, test.expected, actual)
+		}
+	}
+
+	tokens, err := lexExpression("1h30m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Duration(tokens[0].num); d != 90*time.Minute {
+		t.Errorf("expected 1h30m to be 90 minutes, got %v", d)
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+		msg  string
+	}{
+		{"nope == 1", 0, "unknown field 'nope'"},
+		{"extra. == 1", 0, "expected the name of the extra field"},
+		{"status == ", 10, "expected a field or value"},
+		{"status == 200 and", 17, "expected a field or value"},
+		{"(status == 200", 14, "expected ')'"},
+		{"status == 200)", 13, "unexpected ')'"},
+		{`url == "abc`, 7, "unterminated string"},
+		{"status == 1.5", 10, "expected a whole number"},
+		{"status @ 1", 7, "unexpected character '@'"},
+		{`status == "200"`, 7, "cannot use status (number) == \"200\" (string)"},
+		{"url > 5", 4, "cannot use url (string) > 5 (number)"},
+		{"duration > 5", 11, "use a duration e.g. 1s or 500ms"},
+		{`status =~ "2.."`, 0, "cannot use =~ with status, which is a number"},
+		{`url =~ "("`, 7, "invalid regex"},
+		{"url =~ path", 7, "expected a string literal after =~"},
+		{"status in 299..200", 10, "the start is after the end"},
+		{"status in ..", 10, "expected a number before or after .."},
+		{"status in [200, 301", 19, "expected ',' or ']'"},
+		{"length in 4242~-1", 15, "invalid tolerance '-1'"},
+		{"length in 4242~-2%", 15, "invalid tolerance '-2%'"},
+		{"length in 4242~1s", 15, "expected a number e.g. 10 or a percentage"},
+		{"duration in 1s~10", 15, "expected a duration e.g. 100ms or a percentage"},
+		{"length in 1~9223372036854775807", 12, "is too large"},
+		{"success in 1..2", 0, "cannot use in with success, which is a bool"},
+		{`url in "a"`, 7, "expected a list"},
+		{`url in [1]`, 8, "expected a string"},
+		{"duration > 5x", 11, "use a duration e.g. 1s or 500ms"},
+		{"status > 5x", 10, "unexpected 'x'"},
+		{"duration in 1h30", 12, "expected a duration"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseExpression(test.expr)
+
+		var exprErr *ExpressionError
+		if !errors.As(err, &exprErr) {
+			t.Errorf("%s: expected an *ExpressionError, got %v", test.expr, err)
+			continue
+		}
+
+		if exprErr.Pos != test.pos || !strings.Contains(exprErr.Msg, test.msg) {
+			t.Errorf("%s: expected %q at %d, got %q at %d", test.expr, test.msg, test.pos, exprErr.Msg, exprErr.Pos)
+		}
+	}
+}
+
+func TestExpressionTemplateQueries(t *testing.T) {
+	tests := map[string]int{
+		".IsSuccess":                     0,
+		"{{.IsSuccess}}":                 0,
+		"not (or .IsRateLimit .IsError)": 8,
+		`and (.StatusIn "300-399") (.X)`: 5,
+		`.HasParam "debug"`:              0,
+	}
+
+	for expr, pos := range tests {
+		_, err := ParseExpression(expr)
+
+		var exprErr *ExpressionError
+		if !errors.As(err, &exprErr) {
+			t.Errorf("%s: expected an *ExpressionError, got %v", expr, err)
+			continue
+		}
+
+		if exprErr.Pos != pos || !strings.Contains(exprErr.Msg, "template queries are no longer supported") {
+			t.Errorf("%s: expected the template query error at %d, got %q at %d", expr, pos, exprErr.Msg, exprErr.Pos)
+		}
+	}
+
+	// Valid expressions are never mistaken for template queries
+	if _, err := ParseExpression(`url contains " .Net"`); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExpressionErrorMessage(t *testing.T) {
+	_, err := ParseExpression("status == ")
+	expected := "invalid expression at position 11: expected a field or value, found 'end of expression'\n  status == \n            ^"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected\n%s\ngot\n%v", expected, err)
+	}
+}
+
+func TestWhereNoMatches(t *testing.T) {
+	matches, err := exprResults.Where("status == 999")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matches == nil || len(matches) != 0 {
+		t.Errorf("expected an empty non-nil result, got %#v", matches)
+	}
+}
+
+func TestExpressionString(t *testing.T) {
+	e, err := ParseExpression("status in 200..299")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.String() != "status in 200..299" {
+		t.Errorf("unexpected string %q", e.String())
+	}
+}
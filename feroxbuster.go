@@ -8,8 +8,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-
-	"github.com/iancoleman/orderedmap"
 )
 
 var (
@@ -21,9 +19,11 @@ type feroxResult struct {
 	URL           string            `json:"url"`
 	Status        int               `json:"status"`
 	ContentLength int               `json:"content_length"`
+	Lines         int               `json:"line_count"`
+	Words         int               `json:"word_count"`
 	Headers       map[string]string `json:"headers"`
 
-	raw         interface{}
+	raw         []byte
 	redirect    string
 	contentType string
 }
@@ -35,12 +35,7 @@ func (f *feroxResult) UnmarshalJSON(bytes []byte) (err error) {
 
 	if err = json.Unmarshal(bytes, &foo); err == nil {
 		*f = feroxResult(foo)
-	}
-
-	m := orderedmap.New()
-
-	if err = json.Unmarshal(bytes, &m); err == nil {
-		f.raw = m
+		f.raw = append([]byte(nil), bytes...)
 
 		if redirect, ok := foo.Headers["location"]; ok {
 			f.redirect = redirect
@@ -68,7 +63,7 @@ func (FeroxbusterParser) isJSONResult(line string) bool {
 		return false
 	}
 
-	return result.URL != ""
+	return result.Type != "" && result.URL != ""
 }
 
 func (FeroxbusterParser) parseJSON(input string) (CDResults, error) {
@@ -92,7 +87,9 @@ func (FeroxbusterParser) parseJSON(input string) (CDResults, error) {
 			Redirect:      result.redirect,
 			ContentType:   result.contentType,
 			ContentLength: result.ContentLength,
-			source:        result.raw,
+			Words:         result.Words,
+			Lines:         result.Lines,
+			source:        newRecord(result.raw, RecordJSON, len(results)),
 		})
 	}
 
@@ -118,13 +115,17 @@ func (FeroxbusterParser) parseText(input string) (CDResults, error) {
 
 		status, _ := strconv.Atoi(namedMatches["status"])
 		length, _ := strconv.Atoi(namedMatches["length"])
+		words, _ := strconv.Atoi(namedMatches["words"])
+		lines, _ := strconv.Atoi(namedMatches["lines"])
 
 		result := CDResult{
 			Url:           namedMatches["url"],
 			Status:        status,
 			ContentType:   "",
 			ContentLength: length,
-			source:        line,
+			Words:         words,
+			Lines:         lines,
+			source:        newTextRecord(line, len(results)),
 		}
 
 		if result.IsRedirect() {
@@ -147,44 +148,47 @@ func (p FeroxbusterParser) Parse(input string) (CDResults, error) {
 }
 
 func (p FeroxbusterParser) CanParse(input string) bool {
-	line := strings.Split(input, "\n")[0]
-	return p.isJSONResult(line) || p.isTextResult(line)
+	lines := strings.Split(input, "\n")
+	if p.isJSONResult(lines[0]) {
+		return true
+	}
+
+	// The text output may start with the banner
+	for _, line := range lines {
+		if p.isTextResult(line) {
+			return true
+		}
+	}
+	return false
 }
 
 func (p FeroxbusterParser) CanTransform() bool {
 	return true
 }
 
-func (p FeroxbusterParser) transformJSON(filtered []interface{}) (string, error) {
-	writer := bytes.NewBuffer(nil)
-
-	for _, line := range filtered {
-		bytes, err := json.Marshal(line)
-		if err != nil {
-			return "", err
-		}
-
-		writer.WriteString(fmt.Sprintln(string(bytes)))
+func (p FeroxbusterParser) transformJSON(records []Record) (string, error) {
+	err := checkRecords(records, RecordJSON)
+	if err != nil {
+		return "", err
 	}
 
-	return writer.String(), nil
-}
-
-func (p FeroxbusterParser) transformText(filtered []interface{}) (string, error) {
 	writer := bytes.NewBuffer(nil)
-
-	for _, line := range filtered {
-		writer.WriteString(fmt.Sprintln(line))
+	for _, record := range records {
+		writer.WriteString(fmt.Sprintln(record.String()))
 	}
 
 	return writer.String(), nil
 }
 
-func (p FeroxbusterParser) Transform(input string, filtered []interface{}) (string, error) {
+func (p FeroxbusterParser) transformText(input string, records []Record) (string, error) {
+	return transformLines(input, records, p.isTextResult)
+}
+
+func (p FeroxbusterParser) Transform(input string, records []Record) (string, error) {
 	line := strings.Split(input, "\n")[0]
 	if p.isJSONResult(line) {
-		return p.transformJSON(filtered)
+		return p.transformJSON(records)
 	}
 
-	return p.transformText(filtered)
+	return p.transformText(input, records)
 }
@@ -0,0 +1,47 @@
+package gocdp
+
+import (
+	"strings"
+	"testing"
+)
+
+const feroxbusterInput = " ___  ___  __   __     __      __         __   ___\r\n" +
+	" by Ben \"epi\" Risher 🤓                 ver: 2.10.1\r\n" +
+	"───────────────────────────┬──────────────────────\r\n" +
+	" 🎯  Target Url            │ http://web.example.com\r\n" +
+	" 🚀  Threads               │ 50\r\n" +
+	"───────────────────────────┴──────────────────────\r\n" +
+	"WLD      GET        7l       12w      168c Got 404 for http://web.example.com/abc123 (url length: 32)\r\n" +
+	"403      GET        7l       10w      199c http://web.example.com/admin\r\n" +
+	"200      GET       25l       80w      612c http://web.example.com/index.html\r\n" +
+	"MSG      0.000 feroxbuster::heuristics detected directory listing: http://web.example.com/files\r\n" +
+	"301      GET        1l        1w        0c http://web.example.com/old => http://web.example.com/old/\r\n" +
+	"[####################] - 3s      4614/4614    0s      found:3       errors:0\r\n"
+
+func TestFeroxbusterTransformKeepsNonResultLines(t *testing.T) {
+	output, err := SmartTrim(strings.NewReader(feroxbusterInput), []TrimOption{WithFilterStatus(403, 301)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Replace(feroxbusterInput, "403      GET        7l       10w      199c http://web.example.com/admin\r\n", "", 1)
+	expected = strings.Replace(expected, "301      GET        1l        1w        0c http://web.example.com/old => http://web.example.com/old/\r\n", "", 1)
+	if output != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, output)
+	}
+}
+
+func TestFeroxbusterTransformJSON(t *testing.T) {
+	input := `{"type":"response","url":"http://web.example.com/admin","status":403,"content_length":199,"line_count":7,"word_count":10,"headers":{}}
+{"type":"response","url":"http://web.example.com/index.html","status":200,"content_length":612,"line_count":25,"word_count":80,"headers":{"content-type":"text/html"}}
+`
+
+	output, err := SmartTrim(strings.NewReader(input), []TrimOption{WithFilterStatus(403)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := strings.SplitAfter(input, "\n")[1]; output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
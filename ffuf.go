@@ -2,6 +2,8 @@ package gocdp
 
 import (
 	"encoding/json"
+	"path/filepath"
+	"time"
 
 	"github.com/iancoleman/orderedmap"
 )
@@ -10,7 +12,10 @@ type ffufOutput struct {
 	CommandLine string       `json:"commandline"`
 	Time        string       `json:"time"`
 	Results     []ffufResult `json:"results"`
-	Config      interface{}  `json:"config"`
+	Config      ffufConfig   `json:"config"`
+}
+type ffufConfig struct {
+	OutputDirectory string `json:"outputdirectory"`
 }
 type ffufResult struct {
 	URL           string `json:"url"`
@@ -18,7 +23,11 @@ type ffufResult struct {
 	Redirect      string `json:"redirectlocation"`
 	ContentType   string `json:"content-type"`
 	ContentLength int    `json:"length"`
-	raw           interface{}
+	Words         int    `json:"words"`
+	Lines         int    `json:"lines"`
+	Duration      int64  `json:"duration"`
+	ResultFile    string `json:"resultfile"`
+	raw           []byte
 }
 
 type _ffufResult ffufResult
@@ -28,12 +37,7 @@ func (f *ffufResult) UnmarshalJSON(bytes []byte) (err error) {
 
 	if err = json.Unmarshal(bytes, &foo); err == nil {
 		*f = ffufResult(foo)
-	}
-
-	m := orderedmap.New()
-
-	if err = json.Unmarshal(bytes, &m); err == nil {
-		f.raw = m
+		f.raw = append([]byte(nil), bytes...)
 	}
 
 	return err
@@ -51,13 +55,22 @@ func (parser FfufParser) Parse(input string) (CDResults, error) {
 
 	var results CDResults
 	for _, result := range output.Results {
+		responseFile := result.ResultFile
+		if responseFile != "" && output.Config.OutputDirectory != "" {
+			responseFile = filepath.Join(output.Config.OutputDirectory, responseFile)
+		}
+
 		results = append(results, CDResult{
 			Url:           result.URL,
 			Status:        result.Status,
 			Redirect:      result.Redirect,
 			ContentType:   result.ContentType,
 			ContentLength: result.ContentLength,
-			source:        result.raw,
+			Words:         result.Words,
+			Lines:         result.Lines,
+			Duration:      time.Duration(result.Duration),
+			ResponseFile:  responseFile,
+			source:        newRecord(result.raw, RecordJSON, len(results)),
 		})
 	}
 	return results, nil
@@ -76,14 +89,19 @@ func (parser FfufParser) CanParse(input string) bool {
 func (parser FfufParser) CanTransform() bool {
 	return true
 }
-func (p FfufParser) Transform(input string, filtered []interface{}) (string, error) {
+func (p FfufParser) Transform(input string, records []Record) (string, error) {
+	raws, err := jsonRecords(records)
+	if err != nil {
+		return "", err
+	}
+
 	output := orderedmap.New()
-	err := json.Unmarshal([]byte(input), output)
+	err = json.Unmarshal([]byte(input), output)
 	if err != nil {
 		return "", err
 	}
 
-	output.Set("results", filtered)
+	output.Set("results", raws)
 
 	bytes, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
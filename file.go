@@ -0,0 +1,152 @@
+package gocdp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// outputFile returns the file the trimmed output of the file is written to
+func (o *TrimOptions) outputFile(file string) string {
+	output := file
+	if o.outputSuffix != "" {
+		ext := filepath.Ext(output)
+		output = strings.TrimSuffix(output, ext) + o.outputSuffix + ext
+	}
+
+	if o.outputDir != "" {
+		output = filepath.Join(o.outputDir, filepath.Base(output))
+	}
+	return output
+}
+
+// checkOutputFiles returns an error when different files would be written to the same output file
+// e.g. a/ffuf.json and b/ffuf.json with WithOutputDir, so no trimmed output is silently replaced
+func (o *TrimOptions) checkOutputFiles(files []string) error {
+	outputs := make(map[string]string)
+	for _, file := range files {
+		output := filepath.Clean(o.outputFile(file))
+		if other, ok := outputs[output]; ok && !sameFile(other, file) {
+			return fmt.Errorf("'%s' and '%s' would both be written to '%s'", other, file, output)
+		}
+		outputs[output] = file
+	}
+	return nil
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory as the file and renames it over the file,
+// so the file is either fully written or left untouched. The mode and ownership are taken from info, if given
+func writeFileAtomic(file string, data []byte, info os.FileInfo) error {
+	tmp, err := writeTempFile(file, data, info)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp, file)
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// writeTempFile writes the data to a temporary file in the same directory as the file, ready to be renamed over it.
+// The mode and ownership are taken from info, if given
+func writeTempFile(file string, data []byte, info os.FileInfo) (string, error) {
+	dir := filepath.Dir(file)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	perm := os.FileMode(0644)
+	if info != nil {
+		perm = info.Mode().Perm()
+	}
+
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+
+	if err == nil && info != nil {
+		err = chown(tmp.Name(), info)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// copyFileAtomic copies the file to the destination, keeping its mode and ownership
+func copyFileAtomic(src string, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(dst, data, info)
+}
+
+// backupFile copies the file to the backup, unless the backup already exists. The first backup is kept so repeated trims
+// never replace the original file with an already trimmed one. Returns whether the backup was created
+func backupFile(file string, backup string) (bool, error) {
+	if _, err := os.Stat(backup); err == nil {
+		return false, nil
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	err := copyFileAtomic(file, backup)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sameFile returns whether both paths are the same file
+func sameFile(a string, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(aInfo, bInfo)
+}
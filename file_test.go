@@ -0,0 +1,121 @@
+package gocdp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestOutputFile(t *testing.T) {
+	tests := []struct {
+		opts     []TrimOption
+		expected string
+	}{
+		{nil, filepath.Join("scans", "ffuf.json")},
+		{[]TrimOption{WithOutputSuffix(".trimmed")}, filepath.Join("scans", "ffuf.trimmed.json")},
+		{[]TrimOption{WithOutputDir("out")}, filepath.Join("out", "ffuf.json")},
+		{[]TrimOption{WithOutputDir("out"), WithOutputSuffix("-2")}, filepath.Join("out", "ffuf-2.json")},
+	}
+
+	for _, test := range tests {
+		if actual := newTrimOptions(test.opts).outputFile(filepath.Join("scans", "ffuf.json")); actual != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, actual)
+		}
+	}
+}
+
+func TestWriteFileAtomicKeepsMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not kept on windows")
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	if err := os.WriteFile(file, []byte("before"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(file, []byte("after"), info); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "after" {
+		t.Errorf("expected the new content, got %q", data)
+	}
+
+	info, err = os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the mode 0600 to be kept, got %v", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only %s, got %d files", file, len(entries))
+	}
+}
+
+func TestSmartTrimFileOutputSuffix(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b")
+
+	err := SmartTrimFile(file, []TrimOption{WithFilterURL("/b$"), WithOutputSuffix(".trimmed")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if urls := fileURLs(t, file); !reflect.DeepEqual(urls, []string{"http://t/a", "http://t/b"}) {
+		t.Errorf("expected the original to be kept, got %v", urls)
+	}
+	if urls := fileURLs(t, filepath.Join(dir, "f1.trimmed.json")); !reflect.DeepEqual(urls, []string{"http://t/a"}) {
+		t.Errorf("expected the trimmed output, got %v", urls)
+	}
+}
+
+func TestSmartTrimFilesOutputCollision(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a", "ffuf.json")
+	b := filepath.Join(dir, "b", "ffuf.json")
+	out := filepath.Join(dir, "out")
+	for _, file := range []string{a, b} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeFfufFile(t, file, "http://t/a", "http://t/b")
+	}
+
+	for _, opts := range [][]TrimOption{{}, {WithAllOrNothing()}} {
+		opts = append(opts, WithFilterURL("/b$"), WithOutputDir(out))
+		err := SmartTrimFiles([]string{a, b}, opts)
+		if err == nil || !strings.Contains(err.Error(), "would both be written to") {
+			t.Errorf("expected the colliding outputs to fail, got %v", err)
+		}
+
+		if _, err := os.Stat(out); !os.IsNotExist(err) {
+			t.Errorf("expected nothing to be written, got %v", err)
+		}
+	}
+
+	// The same file given twice is not a collision
+	if err := SmartTrimFiles([]string{a, filepath.Join(dir, "a", ".", "ffuf.json")}, []TrimOption{WithFilterURL("/b$"), WithOutputDir(out)}); err != nil {
+		t.Fatal(err)
+	}
+}
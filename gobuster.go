@@ -2,8 +2,6 @@ package gocdp
 
 import (
 	"bufio"
-	"bytes"
-	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -43,7 +41,7 @@ func (GobusterParser) Parse(input string) (CDResults, error) {
 			Status:        status,
 			ContentLength: length,
 			ContentType:   "",
-			source:        line,
+			source:        newTextRecord(line, len(results)),
 		}
 
 		if result.IsRedirect() {
@@ -64,12 +62,6 @@ func (GobusterParser) CanTransform() bool {
 	return true
 }
 
-func (p GobusterParser) Transform(input string, filtered []interface{}) (string, error) {
-	writer := bytes.NewBuffer(nil)
-
-	for _, line := range filtered {
-		writer.WriteString(fmt.Sprintln(line))
-	}
-
-	return writer.String(), nil
+func (p GobusterParser) Transform(input string, records []Record) (string, error) {
+	return transformLines(input, records, gbResultRegex.MatchString)
 }
@@ -0,0 +1,66 @@
+package gocdp
+
+import (
+	"strings"
+	"testing"
+)
+
+const gobusterInput = `===============================================================
+Gobuster v3.6
+===============================================================
+[+] Url:                     http://web.example.com
+[+] Wordlist:                common.txt
+[+] Threads:                 10
+===============================================================
+Starting gobuster in directory enumeration mode
+===============================================================
+http://web.example.com/admin (Status: 403) [Size: 199]
+http://web.example.com/index.html (Status: 200) [Size: 612]
+http://web.example.com/old (Status: 301) [Size: 0] [--> http://web.example.com/old/]
+http://web.example.com/admin (Status: 403) [Size: 199]
+Progress: 4614 / 4615 (99.98%)
+===============================================================
+Finished
+===============================================================
+`
+
+func TestGobusterTransformKeepsBanner(t *testing.T) {
+	output, err := SmartTrim(strings.NewReader(gobusterInput), []TrimOption{WithFilterStatus(403)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Replace(gobusterInput, "http://web.example.com/admin (Status: 403) [Size: 199]\n", "", 2)
+	if output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestGobusterTransformDuplicates(t *testing.T) {
+	// Only the first of the duplicated lines is kept
+	output, err := SmartTrim(strings.NewReader(gobusterInput), []TrimOption{WithMaxResultsBy(1, KeyURL)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count := strings.Count(output, "http://web.example.com/admin (Status: 403)"); count != 1 {
+		t.Errorf("expected the admin result once, got %d times", count)
+	}
+	if !strings.Contains(output, "[+] Wordlist:") || !strings.Contains(output, "Progress: 4614") {
+		t.Errorf("expected the banner and progress to be kept, got:\n%s", output)
+	}
+}
+
+func TestGobusterParserRedirect(t *testing.T) {
+	results, err := GobusterParser{}.Parse(gobusterInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	if results[2].Redirect != "http://web.example.com/old/" || results[0].Redirect != "" {
+		t.Errorf("unexpected redirects %q and %q", results[2].Redirect, results[0].Redirect)
+	}
+}
@@ -0,0 +1,155 @@
+package gocdp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type httpxResult struct {
+	URL           string   `json:"url"`
+	StatusCode    *int     `json:"status_code"`
+	ContentLength int      `json:"content_length"`
+	ContentType   string   `json:"content_type"`
+	Location      string   `json:"location"`
+	Title         string   `json:"title"`
+	Webserver     string   `json:"webserver"`
+	Tech          []string `json:"tech"`
+	Words         int      `json:"words"`
+	Lines         int      `json:"lines"`
+	Time          string   `json:"time"`
+	Failed        bool     `json:"failed"`
+	raw           []byte
+}
+
+type _httpxResult httpxResult
+
+func (h *httpxResult) UnmarshalJSON(bytes []byte) (err error) {
+	foo := _httpxResult{}
+
+	if err = json.Unmarshal(bytes, &foo); err == nil {
+		*h = httpxResult(foo)
+		h.raw = append([]byte(nil), bytes...)
+	}
+
+	return err
+}
+
+// HttpxParser parses the JSON lines output of httpx i.e. httpx -json
+type HttpxParser struct {
+}
+
+func (HttpxParser) isResult(line string) bool {
+	var result httpxResult
+	err := json.Unmarshal([]byte(line), &result)
+	if err != nil {
+		return false
+	}
+
+	return result.URL != "" && (result.StatusCode != nil || result.Failed)
+}
+
+func (HttpxParser) Parse(input string) (CDResults, error) {
+	var results CDResults
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Buffer(nil, 1024*1024*10)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var result httpxResult
+		err := json.Unmarshal(line, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Failed || result.StatusCode == nil {
+			continue
+		}
+
+		extra := make(map[string]interface{})
+		if result.Title != "" {
+			extra["title"] = result.Title
+		}
+		if result.Webserver != "" {
+			extra["webserver"] = result.Webserver
+		}
+		if len(result.Tech) != 0 {
+			extra["tech"] = result.Tech
+		}
+
+		duration, _ := time.ParseDuration(result.Time)
+
+		results = append(results, CDResult{
+			Url:           result.URL,
+			Status:        *result.StatusCode,
+			Redirect:      result.Location,
+			ContentType:   result.ContentType,
+			ContentLength: result.ContentLength,
+			Words:         result.Words,
+			Lines:         result.Lines,
+			Duration:      duration,
+			Extra:         extra,
+			source:        newRecord(result.raw, RecordJSON, len(results)),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (p HttpxParser) CanParse(input string) bool {
+	line := strings.Split(input, "\n")[0]
+	return p.isResult(line)
+}
+
+func (HttpxParser) CanTransform() bool {
+	return true
+}
+
+// isProbedResult returns whether the line is a result returned by Parse, which skips the failed probes
+func (HttpxParser) isProbedResult(line string) bool {
+	var result httpxResult
+	err := json.Unmarshal([]byte(line), &result)
+	if err != nil {
+		return false
+	}
+
+	return !result.Failed && result.StatusCode != nil
+}
+
+// Transform removes the lines of the results which were not kept, keeping every other line such as the failed probes
+func (p HttpxParser) Transform(input string, records []Record) (string, error) {
+	err := checkRecords(records, RecordJSON)
+	if err != nil {
+		return "", err
+	}
+
+	kept := make(map[int]bool)
+	for _, record := range records {
+		kept[record.position] = true
+	}
+
+	var lines []string
+	position := 0
+	for _, line := range strings.Split(input, "\n") {
+		if p.isProbedResult(line) {
+			position += 1
+			if !kept[position-1] {
+				continue
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,107 @@
+package gocdp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const httpxInput = `{"timestamp":"2023-01-01T00:00:00Z","url":"https://a.example.com","input":"a.example.com","title":"Welcome","webserver":"nginx","content_type":"text/html","status_code":200,"content_length":612,"tech":["Nginx"],"words":80,"lines":25,"time":"120ms","failed":false}
+{"timestamp":"2023-01-01T00:00:00Z","url":"https://a.example.com/admin","input":"a.example.com","content_type":"text/html","status_code":301,"content_length":0,"location":"/admin/","words":1,"lines":1,"failed":false}
+{"timestamp":"2023-01-01T00:00:00Z","url":"https://b.example.com","input":"b.example.com","failed":true}
+`
+
+// resultFields formats the common fields of the results for comparison
+func resultFields(results CDResults) []string {
+	fields := []string{}
+	for _, r := range results {
+		fields = append(fields, fmt.Sprintf("%s|%d|%d|%s|%s", r.Url, r.Status, r.ContentLength, r.Redirect, r.ContentType))
+	}
+	return fields
+}
+
+func TestHttpxParser(t *testing.T) {
+	parser := HttpxParser{}
+	if !parser.CanParse(httpxInput) {
+		t.Fatal("expected the httpx output to be parseable")
+	}
+
+	results, err := parser.Parse(httpxInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"https://a.example.com|200|612||text/html",
+		"https://a.example.com/admin|301|0|/admin/|text/html",
+	}
+	if fields := resultFields(results); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+
+	first := results[0]
+	if first.Words != 80 || first.Lines != 25 || first.Duration.String() != "120ms" {
+		t.Errorf("unexpected words, lines or duration %+v", first)
+	}
+
+	extra := map[string]interface{}{"title": "Welcome", "webserver": "nginx", "tech": []string{"Nginx"}}
+	if !reflect.DeepEqual(first.Extra, extra) {
+		t.Errorf("expected extra %v, got %v", extra, first.Extra)
+	}
+}
+
+func TestHttpxParserDetection(t *testing.T) {
+	results, err := SmartParse(strings.NewReader(httpxInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected the httpx parser to be picked, got %d results", len(results))
+	}
+
+	if (HttpxParser{}).CanParse(`{"url":"https://a.example.com"}`) {
+		t.Error("a JSON line without a status should not be parsed as httpx")
+	}
+}
+
+func TestHttpxParserTransform(t *testing.T) {
+	output, err := SmartTrim(strings.NewReader(httpxInput), []TrimOption{WithFilterStatus(301)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The failed probe is not a result, so it is kept as is
+	lines := strings.SplitAfter(httpxInput, "\n")
+	if expected := lines[0] + lines[2]; output != expected {
+		t.Errorf("expected the first result and the failed probe, got\n%s", output)
+	}
+}
+
+func TestHttpxParserTransformKeepsFailedLines(t *testing.T) {
+	input := `{"url":"https://a","status_code":200,"content_length":1}` + "\r\n" +
+		`{"url":"https://b","failed":true}` + "\r\n" +
+		"\r\n" +
+		`{"url":"https://c","status_code":404,"content_length":1}` + "\r\n" +
+		`{"url":"https://c","status_code":404,"content_length":1}` + "\r\n"
+
+	output, err := SmartTrim(strings.NewReader(input), []TrimOption{WithMaxResultsBy(1, KeyURL)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.SplitAfter(input, "\n")
+	if expected := strings.Join(lines[:4], ""); output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+
+	// Filters which do not match the failed probe never remove it
+	output, err = SmartTrim(strings.NewReader(input), []TrimOption{WithKeepMode(), WithFilterURL("^https://a$")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := lines[0] + lines[1] + lines[2]; output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
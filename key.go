@@ -0,0 +1,95 @@
+package gocdp
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// KeyFunc returns the key of a result. Results with the same key are considered the same e.g. when capping results with WithMaxResultsBy
+type KeyFunc func(CDResult) string
+
+// KeyURL is the URL of the result
+func KeyURL(result CDResult) string {
+	return result.Url
+}
+
+// KeyURLPath is the URL of the result without the query string
+func KeyURLPath(result CDResult) string {
+	return urlWithoutQuery(result.Url)
+}
+
+// KeyStatus is the status code of the result
+func KeyStatus(result CDResult) string {
+	return fmt.Sprint(result.Status)
+}
+
+// KeyStatusRange is the status range of the result e.g. 2xx, see GroupByStatusRange. Unknown statuses are StatusUnknown
+func KeyStatusRange(result CDResult) string {
+	if !result.IsProbed() {
+		return fmt.Sprint(StatusUnknown)
+	}
+	return fmt.Sprintf("%dxx", statusRange(result.Status)/100)
+}
+
+// KeyHost is the host of the result's URL, including the port if any
+func KeyHost(result CDResult) string {
+	u, err := url.Parse(result.Url)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// KeyStatusLength is the status code and content length of the result
+func KeyStatusLength(result CDResult) string {
+	return fmt.Sprintf("%d|%d", result.Status, result.ContentLength)
+}
+
+// KeyStatusWordsLines is the status code, number of words and number of lines of the result
+func KeyStatusWordsLines(result CDResult) string {
+	return fmt.Sprintf("%d|%d|%d", result.Status, result.Words, result.Lines)
+}
+
+// KeyRedirect is the redirect URL of the result
+func KeyRedirect(result CDResult) string {
+	return result.Redirect
+}
+
+// KeyContentType is the content type of the result, ignoring parameters such as the charset
+func KeyContentType(result CDResult) string {
+	contentType := strings.SplitN(result.ContentType, ";", 2)[0]
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+var keyFuncs = map[string]KeyFunc{
+	"url":                KeyURL,
+	"url-path":           KeyURLPath,
+	"host":               KeyHost,
+	"status":             KeyStatus,
+	"status-range":       KeyStatusRange,
+	"status-length":      KeyStatusLength,
+	"status-words-lines": KeyStatusWordsLines,
+	"redirect":           KeyRedirect,
+	"content-type":       KeyContentType,
+}
+
+// KeyNames returns the names of the keys accepted by ParseKey
+func KeyNames() []string {
+	var names []string
+	for name := range keyFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseKey returns the key function by its name, see KeyNames
+func ParseKey(name string) (KeyFunc, error) {
+	key, ok := keyFuncs[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown key '%s', expected one of: %s", name, strings.Join(KeyNames(), ", "))
+	}
+	return key, nil
+}
@@ -0,0 +1,89 @@
+package gocdp
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestKeyFuncs(t *testing.T) {
+	result := CDResult{
+		Url:           "http://Web.Example.com:8080/a/b?x=1",
+		Status:        302,
+		ContentLength: 120,
+		Words:         12,
+		Lines:         3,
+		Redirect:      "/login",
+		ContentType:   "Text/HTML; charset=utf-8",
+	}
+
+	tests := map[string]string{
+		"url":                "http://Web.Example.com:8080/a/b?x=1",
+		"url-path":           "http://Web.Example.com:8080/a/b",
+		"host":               "web.example.com:8080",
+		"status":             "302",
+		"status-range":       "3xx",
+		"status-length":      "302|120",
+		"status-words-lines": "302|12|3",
+		"redirect":           "/login",
+		"content-type":       "text/html",
+	}
+
+	for name, expected := range tests {
+		key, err := ParseKey(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := key(result); actual != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, actual)
+		}
+	}
+
+	if key := KeyStatusRange(CDResult{Status: StatusUnknown}); key != "-1" {
+		t.Errorf("expected the unknown status range to be -1, got %q", key)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if _, err := ParseKey("Status-Length"); err != nil {
+		t.Errorf("expected key names to be case insensitive, got %v", err)
+	}
+
+	if _, err := ParseKey("length"); err == nil {
+		t.Error("expected an unknown key to be an error")
+	}
+
+	names := KeyNames()
+	if len(names) != len(keyFuncs) || !sort.StringsAreSorted(names) {
+		t.Errorf("expected the sorted key names, got %v", names)
+	}
+}
+
+func TestWithMaxResultsBy(t *testing.T) {
+	results := CDResults{
+		{Url: "http://a.example.com/1", Status: 200, ContentLength: 10},
+		{Url: "http://a.example.com/2", Status: 200, ContentLength: 10},
+		{Url: "http://a.example.com/3", Status: 404, ContentLength: 10},
+		{Url: "http://b.example.com/1", Status: 200, ContentLength: 20},
+		{Url: "http://b.example.com/2", Status: 200, ContentLength: 10},
+	}
+
+	tests := []struct {
+		name     string
+		opt      TrimOption
+		expected []string
+	}{
+		{"status", WithMaxResults(1), []string{"http://a.example.com/1", "http://a.example.com/3"}},
+		{"host", WithMaxResultsBy(2, KeyHost), []string{"http://a.example.com/1", "http://a.example.com/2", "http://b.example.com/1", "http://b.example.com/2"}},
+		{"status length", WithMaxResultsBy(1, KeyStatusLength), []string{"http://a.example.com/1", "http://a.example.com/3", "http://b.example.com/1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if urls := trimURLs(t, results, test.opt); !reflect.DeepEqual(urls, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, urls)
+			}
+		})
+	}
+}
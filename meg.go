@@ -0,0 +1,158 @@
+package gocdp
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	megIndexRegex *regexp.Regexp = regexp.MustCompile(`^(?P<file>[^\s]+)\s+(?P<url>https?://[^\s]+)\s+\((?P<status>[0-9]+)[^)]*\)\s*$`)
+)
+
+// MegParser parses the index file of a meg output directory. The headers of each result
+// are read from the stored response files to fill in the content type, length and redirect.
+// Dir is the meg output directory used to find the stored responses.
+type MegParser struct {
+	Dir string
+}
+
+// responseFile returns the path to the stored response. The paths in the index are relative
+// to where meg was run, which is usually the parent of the output directory
+func (p MegParser) responseFile(file string) string {
+	if filepath.IsAbs(file) || p.Dir == "" {
+		return file
+	}
+
+	candidates := []string{
+		filepath.Join(filepath.Dir(p.Dir), file),
+	}
+
+	parts := strings.SplitN(filepath.ToSlash(file), "/", 2)
+	if len(parts) == 2 {
+		candidates = append(candidates, filepath.Join(p.Dir, parts[1]))
+	}
+	candidates = append(candidates, filepath.Join(p.Dir, file))
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return candidates[0]
+}
+
+// readResponse fills in the result from the headers and body of the stored response
+func (MegParser) readResponse(result *CDResult) error {
+	data, err := os.ReadFile(result.ResponseFile)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string)
+	inHeaders := false
+	body := -1
+
+	for offset := 0; offset < len(data); {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end == -1 {
+			end = len(data) - offset
+		}
+
+		line := strings.TrimRight(string(data[offset:offset+end]), "\r")
+		offset += end + 1
+
+		if strings.HasPrefix(line, "< ") {
+			inHeaders = true
+
+			header := strings.SplitN(line[2:], ":", 2)
+			if len(header) == 2 {
+				headers[strings.ToLower(strings.TrimSpace(header[0]))] = strings.TrimSpace(header[1])
+			}
+			continue
+		}
+
+		if inHeaders {
+			body = offset
+			break
+		}
+	}
+
+	result.ContentType = headers["content-type"]
+	result.Redirect = headers["location"]
+
+	if length, err := strconv.Atoi(headers["content-length"]); err == nil {
+		result.ContentLength = length
+	} else if body != -1 && body <= len(data) {
+		result.ContentLength = len(data) - body
+	}
+
+	return nil
+}
+
+func (p MegParser) Parse(input string) (CDResults, error) {
+	var results CDResults
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		match := megIndexRegex.FindStringSubmatch(line)
+		if len(match) == 0 {
+			continue
+		}
+
+		namedMatches := make(map[string]string)
+		for j, name := range megIndexRegex.SubexpNames() {
+			if j != 0 && name != "" {
+				namedMatches[name] = match[j]
+			}
+		}
+
+		status, _ := strconv.Atoi(namedMatches["status"])
+
+		result := CDResult{
+			Url:           namedMatches["url"],
+			Status:        status,
+			ContentLength: LengthUnknown,
+			ResponseFile:  p.responseFile(namedMatches["file"]),
+			source:        newTextRecord(line, len(results)),
+		}
+
+		if err := p.readResponse(&result); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (MegParser) CanParse(input string) bool {
+	found := false
+	for _, line := range strings.Split(input, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if !megIndexRegex.MatchString(line) {
+			return false
+		}
+		found = true
+	}
+
+	return found
+}
+
+func (MegParser) CanTransform() bool {
+	return true
+}
+
+func (MegParser) Transform(input string, records []Record) (string, error) {
+	return writeTextRecords(records)
+}
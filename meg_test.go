@@ -0,0 +1,102 @@
+package gocdp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const megIndexInput = `out/web.example.com/aaa111 https://web.example.com/robots.txt (200 OK)
+out/web.example.com/bbb222 https://web.example.com/admin (302 Found)
+`
+
+// writeMegDir writes a meg output directory with the index and stored responses and returns its path
+func writeMegDir(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), "out")
+	files := map[string]string{
+		"index": megIndexInput,
+		"web.example.com/aaa111": "https://web.example.com/robots.txt\n\n> GET /robots.txt HTTP/1.1\n> Host: web.example.com\n\n" +
+			"< HTTP/1.1 200 OK\r\n< Content-Type: text/plain\r\n\nUser-agent: *\nDisallow: /\n",
+		"web.example.com/bbb222": "https://web.example.com/admin\n\n> GET /admin HTTP/1.1\n> Host: web.example.com\n\n" +
+			"< HTTP/1.1 302 Found\n< Location: /login\n< Content-Length: 0\n",
+	}
+
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestMegParser(t *testing.T) {
+	dir := writeMegDir(t)
+
+	results, err := SmartParseDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"https://web.example.com/robots.txt|200|26||text/plain",
+		"https://web.example.com/admin|302|0|/login|",
+	}
+	if fields := resultFields(results); !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected %v, got %v", expected, fields)
+	}
+
+	if expected := filepath.Join(dir, "web.example.com", "aaa111"); results[0].ResponseFile != expected {
+		t.Errorf("expected response file %s, got %s", expected, results[0].ResponseFile)
+	}
+}
+
+func TestMegParserMissingResponses(t *testing.T) {
+	results, err := MegParser{Dir: filepath.Join(t.TempDir(), "out")}.Parse(megIndexInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"https://web.example.com/robots.txt|200|-1||",
+		"https://web.example.com/admin|302|-1||",
+	}
+	if fields := resultFields(results); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+}
+
+func TestMegParserCanParse(t *testing.T) {
+	inputs := []string{
+		"",
+		"out/web.example.com/aaa111 https://web.example.com/robots.txt\n",
+		megIndexInput + "not an index line\n",
+	}
+
+	for _, input := range inputs {
+		if (MegParser{}).CanParse(input) {
+			t.Errorf("%q should not be parsed as a meg index", input)
+		}
+	}
+}
+
+func TestMegParserTransform(t *testing.T) {
+	dir := writeMegDir(t)
+
+	output, err := SmartTrim(strings.NewReader(megIndexInput), []TrimOption{WithFilterStatus(302)}, MegParser{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "out/web.example.com/aaa111 https://web.example.com/robots.txt (200 OK)\n"; output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
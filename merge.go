@@ -0,0 +1,81 @@
+package gocdp
+
+import "time"
+
+// UniqueBy de-duplicates the results by the key, keeping the richest of the duplicates i.e. the one with the most known fields.
+// The first of equally rich duplicates is kept, in the position of the first duplicate
+func (results CDResults) UniqueBy(key KeyFunc) CDResults {
+	var unique CDResults
+
+	index := make(map[string]int)
+	for _, result := range results {
+		k := key(result)
+		i, found := index[k]
+		if !found {
+			index[k] = len(unique)
+			unique = append(unique, result)
+			continue
+		}
+
+		if result.richness() > unique[i].richness() {
+			unique[i] = result
+		}
+	}
+
+	return unique
+}
+
+// richness is the number of known fields of the result
+func (result CDResult) richness() int {
+	fields := []bool{
+		result.IsProbed(),
+		result.ContentLength != LengthUnknown,
+		result.Redirect != "",
+		result.ContentType != "",
+		result.Words > 0,
+		result.Lines > 0,
+		result.Duration > 0,
+		len(result.Params) > 0,
+		result.ResponseFile != "",
+	}
+
+	richness := len(result.Extra)
+	for _, known := range fields {
+		if known {
+			richness += 1
+		}
+	}
+	return richness
+}
+
+// MergeFiles parses the files, de-duplicates the results by the key keeping the richest of the duplicates, see UniqueBy,
+// and renders them as a single file. The metadata of the merged scans is not kept, as a single file only has room for one scan.
+// Instead, the command line of the rendered file lists the merged files and the time is when they were merged
+func (cdp *CDP) MergeFiles(files []string, key KeyFunc, render RenderFunc) (string, error) {
+	results, err := cdp.SmartParseFiles(files)
+	if err != nil {
+		return "", err
+	}
+
+	return render(results.UniqueBy(key), ScanInfo{
+		Time:    time.Now(),
+		Sources: results.files(),
+	})
+}
+
+// files returns the files the results were parsed from, in order of appearance
+func (results CDResults) files() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if result.file != "" && !seen[result.file] {
+			seen[result.file] = true
+			files = append(files, result.file)
+		}
+	}
+	return files
+}
+
+func MergeFiles(files []string, key KeyFunc, render RenderFunc) (string, error) {
+	return c.MergeFiles(files, key, render)
+}
@@ -0,0 +1,89 @@
+package gocdp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUniqueByKeepsRichest(t *testing.T) {
+	results := CDResults{
+		{Url: "http://t/a", Status: StatusUnknown, ContentLength: LengthUnknown},
+		{Url: "http://t/b", Status: 200},
+		{Url: "http://t/a", Status: 301, Redirect: "http://t/a/", ContentType: "text/html"},
+		{Url: "http://t/a", Status: 200},
+	}
+
+	unique := results.UniqueBy(KeyURL)
+	if len(unique) != 2 {
+		t.Fatalf("expected 2 results, got %v", unique)
+	}
+
+	if unique[0].Url != "http://t/a" || unique[0].Status != 301 {
+		t.Errorf("expected the richest duplicate in the position of the first, got %+v", unique[0])
+	}
+	if unique[1].Url != "http://t/b" {
+		t.Errorf("expected http://t/b second, got %+v", unique[1])
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	ffuf := filepath.Join(dir, "ffuf.json")
+	writeFfufFile(t, ffuf, "http://a.example.com/x", "http://b.example.com/y")
+
+	gobuster := filepath.Join(dir, "gobuster.txt")
+	err := os.WriteFile(gobuster, []byte("http://a.example.com/x (Status: 200) [Size: 10]\nhttp://a.example.com/z (Status: 403) [Size: 5]\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	empty := filepath.Join(dir, "notes.txt")
+	err = os.WriteFile(empty, []byte("not a scan\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := MergeFiles([]string{ffuf, empty, gobuster}, KeyURL, RenderFfufJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := SmartParse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var urls []string
+	for _, result := range results {
+		urls = append(urls, result.Url)
+	}
+	expected := []string{"http://a.example.com/x", "http://b.example.com/y", "http://a.example.com/z"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected %v, got %v", expected, urls)
+	}
+
+	if commandLine := "gocdp merge " + ffuf + " " + gobuster; !strings.Contains(output, commandLine) {
+		t.Errorf("expected the command line to list the merged files %q\n%s", commandLine, output)
+	}
+}
+
+func TestRenderDirbBase(t *testing.T) {
+	single, err := RenderDirb(CDResults{{Url: "http://a.example.com/x", Status: 200}}, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(single, "URL_BASE: http://a.example.com/\n") {
+		t.Errorf("expected the URL base\n%s", single)
+	}
+
+	merged, err := RenderDirb(CDResults{{Url: "http://a.example.com/x", Status: 200}, {Url: "http://b.example.com/y", Status: 200}}, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(merged, "URL_BASE") {
+		t.Errorf("expected no URL base for many bases\n%s", merged)
+	}
+}
@@ -3,6 +3,14 @@ package gocdp
 import (
 	"sort"
 	"strings"
+	"time"
+)
+
+const (
+	// StatusUnknown is the status of results which were never probed e.g. URLs from passive sources
+	StatusUnknown = -1
+	// LengthUnknown is the content length of results whose response size is not known
+	LengthUnknown = -1
 )
 
 var statusCodeGroups = []int{
@@ -15,22 +23,17 @@ var statusCodeGroups = []int{
 
 type CDResults []CDResult
 
-// GroupByStatusRange groups the results by status ranges e.g. all results with the status code in the range of 200 - 299 are grouped
+// GroupByStatusRange groups the results by status ranges e.g. all results with the status code in the range of 200 - 299 are grouped.
+// Results with an unknown status are grouped under StatusUnknown
 func (results CDResults) GroupByStatusRange() map[int][]CDResult {
 	grouped := make(map[int][]CDResult)
 	for _, result := range results {
-		var status int
-		for i, code := range statusCodeGroups {
-			if i == len(statusCodeGroups)-1 {
-				status = code
-				break
-			}
-
-			if result.Status >= code && result.Status < statusCodeGroups[i+1] {
-				status = code
-				break
-			}
+		if !result.IsProbed() {
+			grouped[StatusUnknown] = append(grouped[StatusUnknown], result)
+			continue
 		}
+
+		status := statusRange(result.Status)
 		grouped[status] = append(grouped[status], result)
 	}
 
@@ -47,7 +50,32 @@ func (results CDResults) GroupByStatusRange() map[int][]CDResult {
 	return grouped
 }
 
-// GroupByStatus groups the results by the status code e.g. all results with the status code of 302 are grouped
+// statusRange returns the start of the status range of the status code e.g. 200 for 204
+func statusRange(status int) int {
+	for i, code := range statusCodeGroups {
+		if i == len(statusCodeGroups)-1 {
+			return code
+		}
+
+		if status >= code && status < statusCodeGroups[i+1] {
+			return code
+		}
+	}
+	return 0
+}
+
+// GroupBy groups the results by the key, keeping the order of the results within each group
+func (results CDResults) GroupBy(key KeyFunc) map[string][]CDResult {
+	grouped := make(map[string][]CDResult)
+	for _, result := range results {
+		k := key(result)
+		grouped[k] = append(grouped[k], result)
+	}
+	return grouped
+}
+
+// GroupByStatus groups the results by the status code e.g. all results with the status code of 302 are grouped.
+// Results with an unknown status are grouped under StatusUnknown
 func (results CDResults) GroupByStatus() map[int][]CDResult {
 	grouped := make(map[int][]CDResult)
 	for _, result := range results {
@@ -90,8 +118,39 @@ type CDResult struct {
 	Redirect      string
 	ContentType   string
 	ContentLength int
+	Words         int
+	Lines         int
+	Duration      time.Duration
+
+	// Params are the discovered parameters and ParamLocation where they go i.e. query, body or header
+	Params        []string `json:",omitempty"`
+	ParamLocation string   `json:",omitempty"`
+
+	// ResponseFile is the path to the stored response, if the tool stores them e.g. meg or ffuf with -od
+	ResponseFile string `json:",omitempty"`
+
+	// Extra holds tool specific fields which have no dedicated field e.g. the title and tech from httpx
+	Extra map[string]interface{} `json:",omitempty"`
+
+	source Record
+	// file is the file the result was parsed from, if any
+	file string
+}
+
+// Source returns the record the result was parsed from, which can be passed to the Transform of the same parser.
+// The format of the record is RecordNone when the parser does not keep the records
+func (result CDResult) Source() Record {
+	return result.source
+}
 
-	source interface{}
+// Raw returns the record the result was parsed from as the tool wrote it e.g. the ffuf JSON object or the gobuster line
+func (result CDResult) Raw() string {
+	return result.source.String()
+}
+
+// IsProbed returns whether the status of the result is known
+func (result CDResult) IsProbed() bool {
+	return result.Status != StatusUnknown
 }
 
 func (result CDResult) IsRedirect() bool {
@@ -110,6 +169,18 @@ func (result CDResult) IsRateLimit() bool {
 	return result.Status == 429
 }
 
+// HasParam returns whether any of the parameters were discovered for the result
+func (result CDResult) HasParam(params ...string) bool {
+	for _, param := range params {
+		for _, p := range result.Params {
+			if p == param {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (result CDResult) IsStatus(statusCodes ...int) bool {
 	for _, status := range statusCodes {
 		if result.Status == status {
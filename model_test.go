@@ -0,0 +1,74 @@
+package gocdp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupByStatusRange(t *testing.T) {
+	results := CDResults{
+		{Url: "http://t/a", Status: 204},
+		{Url: "http://t/b", Status: StatusUnknown},
+		{Url: "http://t/c", Status: 404},
+		{Url: "http://t/d", Status: 200},
+		{Url: "http://t/e", Status: 599},
+	}
+
+	grouped := results.GroupByStatusRange()
+
+	expected := map[int][]string{
+		200:           {"http://t/d", "http://t/a"},
+		400:           {"http://t/c"},
+		500:           {"http://t/e"},
+		StatusUnknown: {"http://t/b"},
+	}
+
+	if len(grouped) != len(expected) {
+		t.Fatalf("expected %d groups, got %v", len(expected), grouped)
+	}
+	for status, urls := range expected {
+		var actual []string
+		for _, result := range grouped[status] {
+			actual = append(actual, result.Url)
+		}
+		if !reflect.DeepEqual(actual, urls) {
+			t.Errorf("%d: expected %v, got %v", status, urls, actual)
+		}
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	results := CDResults{
+		{Url: "http://a.example.com/x"},
+		{Url: "http://b.example.com/y"},
+		{Url: "http://a.example.com/z"},
+	}
+
+	grouped := results.GroupBy(KeyHost)
+	if len(grouped) != 2 || len(grouped["a.example.com"]) != 2 || grouped["a.example.com"][1].Url != "http://a.example.com/z" {
+		t.Errorf("unexpected groups %v", grouped)
+	}
+}
+
+func TestResultStatusHelpers(t *testing.T) {
+	tests := []struct {
+		result   CDResult
+		expected [6]bool
+	}{
+		// probed, redirect, success, error, auth error, rate limit
+		{CDResult{Status: StatusUnknown}, [6]bool{false, false, false, false, false, false}},
+		{CDResult{Status: 200}, [6]bool{true, false, true, false, false, false}},
+		{CDResult{Status: 200, Redirect: "/x"}, [6]bool{true, true, true, false, false, false}},
+		{CDResult{Status: 302}, [6]bool{true, true, false, false, false, false}},
+		{CDResult{Status: 403}, [6]bool{true, false, false, true, true, false}},
+		{CDResult{Status: 429}, [6]bool{true, false, false, true, false, true}},
+	}
+
+	for _, test := range tests {
+		r := test.result
+		actual := [6]bool{r.IsProbed(), r.IsRedirect(), r.IsSuccess(), r.IsError(), r.IsAuthError(), r.IsRateLimit()}
+		if actual != test.expected {
+			t.Errorf("%+v: expected %v, got %v", r, test.expected, actual)
+		}
+	}
+}
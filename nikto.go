@@ -0,0 +1,194 @@
+package gocdp
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+)
+
+type niktoJSONHost struct {
+	Host            string `json:"host"`
+	IP              string `json:"ip"`
+	Port            string `json:"port"`
+	Vulnerabilities []struct {
+		ID     string `json:"id"`
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		Msg    string `json:"msg"`
+	} `json:"vulnerabilities"`
+}
+
+type niktoXMLScanDetails struct {
+	TargetIP       string `xml:"targetip,attr"`
+	TargetHostname string `xml:"targethostname,attr"`
+	TargetPort     string `xml:"targetport,attr"`
+	SiteName       string `xml:"sitename,attr"`
+	Items          []struct {
+		ID          string `xml:"id,attr"`
+		Method      string `xml:"method,attr"`
+		Description string `xml:"description"`
+		URI         string `xml:"uri"`
+		NameLink    string `xml:"namelink"`
+	} `xml:"item"`
+}
+
+// NiktoParser parses the paths reported in Nikto JSON and XML output i.e. nikto -Format json|xml
+type NiktoParser struct {
+}
+
+func (NiktoParser) parseJSONHosts(input string) ([]niktoJSONHost, error) {
+	input = strings.TrimSpace(input)
+	if strings.HasPrefix(input, "[") {
+		var hosts []niktoJSONHost
+		err := json.Unmarshal([]byte(input), &hosts)
+		return hosts, err
+	}
+
+	var host niktoJSONHost
+	err := json.Unmarshal([]byte(input), &host)
+	return []niktoJSONHost{host}, err
+}
+
+func (NiktoParser) parseXMLScans(input string) ([]niktoXMLScanDetails, error) {
+	var scans []niktoXMLScanDetails
+
+	decoder := xml.NewDecoder(strings.NewReader(input))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "scandetails" {
+			continue
+		}
+
+		var scan niktoXMLScanDetails
+		err = decoder.DecodeElement(&scan, &start)
+		if err != nil {
+			return nil, err
+		}
+		scans = append(scans, scan)
+	}
+
+	return scans, nil
+}
+
+func (p NiktoParser) isJSONResult(input string) bool {
+	hosts, err := p.parseJSONHosts(input)
+	if err != nil || len(hosts) == 0 {
+		return false
+	}
+
+	return hosts[0].Host != "" && hosts[0].Port != ""
+}
+
+func (p NiktoParser) isXMLResult(input string) bool {
+	if !strings.Contains(input, "<niktoscan") {
+		return false
+	}
+
+	_, err := p.parseXMLScans(input)
+	return err == nil
+}
+
+func (p NiktoParser) parseJSON(input string) (CDResults, error) {
+	hosts, err := p.parseJSONHosts(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results CDResults
+	for _, host := range hosts {
+		scheme := "http"
+		if host.Port == "443" {
+			scheme = "https"
+		}
+
+		name := host.Host
+		if name == "" {
+			name = host.IP
+		}
+
+		for _, vuln := range host.Vulnerabilities {
+			results = append(results, CDResult{
+				Url:           buildURL(scheme, name, host.Port, vuln.URL),
+				Status:        StatusUnknown,
+				ContentLength: LengthUnknown,
+				Extra: map[string]interface{}{
+					"id":     vuln.ID,
+					"method": vuln.Method,
+					"note":   vuln.Msg,
+				},
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func (p NiktoParser) parseXML(input string) (CDResults, error) {
+	scans, err := p.parseXMLScans(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results CDResults
+	for _, scan := range scans {
+		scheme := "http"
+		if u, err := url.Parse(scan.SiteName); err == nil && u.Scheme != "" {
+			scheme = u.Scheme
+		} else if scan.TargetPort == "443" {
+			scheme = "https"
+		}
+
+		name := scan.TargetHostname
+		if name == "" {
+			name = scan.TargetIP
+		}
+
+		for _, item := range scan.Items {
+			results = append(results, CDResult{
+				Url:           buildURL(scheme, name, scan.TargetPort, strings.TrimSpace(item.URI)),
+				Status:        StatusUnknown,
+				ContentLength: LengthUnknown,
+				Extra: map[string]interface{}{
+					"id":     item.ID,
+					"method": item.Method,
+					"note":   strings.TrimSpace(item.Description),
+				},
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func (p NiktoParser) Parse(input string) (CDResults, error) {
+	if p.isJSONResult(input) {
+		return p.parseJSON(input)
+	} else if p.isXMLResult(input) {
+		return p.parseXML(input)
+	}
+
+	return nil, nil
+}
+
+func (p NiktoParser) CanParse(input string) bool {
+	return p.isJSONResult(input) || p.isXMLResult(input)
+}
+
+func (NiktoParser) CanTransform() bool {
+	return false
+}
+
+func (NiktoParser) Transform(input string, records []Record) (string, error) {
+	return "", errors.New("nikto output cannot be transformed")
+}
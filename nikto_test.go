@@ -0,0 +1,90 @@
+package gocdp
+
+import (
+	"reflect"
+	"testing"
+)
+
+const niktoJSONInput = `[{"host":"web.example.com","ip":"10.0.0.1","port":"443","banner":"nginx","vulnerabilities":[{"id":"999986","references":"","method":"GET","url":"/","msg":"The anti-clickjacking X-Frame-Options header is not present."},{"id":"002","method":"GET","url":"/backup/","msg":"Directory indexing found."}]}]`
+
+const niktoXMLInput = `<?xml version="1.0" ?>
+<niktoscans><niktoscan hoststest="0" options="-h web.example.com -Format xml" version="2.5.0" nxmlversion="1.2">
+<scandetails targetip="10.0.0.1" targethostname="web.example.com" targetport="8080" sitename="http://web.example.com:8080/" hostheader="web.example.com" errors="0" checks="10">
+<item id="999100" osvdbid="0" osvdblink="" method="GET">
+<description><![CDATA[/admin/: Admin login page/section found.]]></description>
+<uri><![CDATA[/admin/]]></uri>
+<namelink><![CDATA[http://web.example.com:8080/admin/]]></namelink>
+</item>
+</scandetails>
+</niktoscan></niktoscans>
+`
+
+func TestNiktoParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		extra    map[string]interface{}
+	}{
+		{
+			name:  "json",
+			input: niktoJSONInput,
+			expected: []string{
+				"https://web.example.com/|-1|-1||",
+				"https://web.example.com/backup/|-1|-1||",
+			},
+			extra: map[string]interface{}{
+				"id":     "999986",
+				"method": "GET",
+				"note":   "The anti-clickjacking X-Frame-Options header is not present.",
+			},
+		},
+		{
+			name:  "xml",
+			input: niktoXMLInput,
+			expected: []string{
+				"http://web.example.com:8080/admin/|-1|-1||",
+			},
+			extra: map[string]interface{}{
+				"id":     "999100",
+				"method": "GET",
+				"note":   "/admin/: Admin login page/section found.",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := NiktoParser{}
+			if !parser.CanParse(test.input) {
+				t.Fatal("expected the nikto output to be parseable")
+			}
+
+			results, err := parser.Parse(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fields := resultFields(results); !reflect.DeepEqual(fields, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, fields)
+			}
+			if !reflect.DeepEqual(results[0].Extra, test.extra) {
+				t.Errorf("expected extra %v, got %v", test.extra, results[0].Extra)
+			}
+		})
+	}
+}
+
+func TestNiktoParserCanParse(t *testing.T) {
+	inputs := []string{
+		`{"results":[]}`,
+		`[{"url":"http://web.example.com/","status-code":200}]`,
+		nmapInput,
+	}
+
+	for _, input := range inputs {
+		if (NiktoParser{}).CanParse(input) {
+			t.Errorf("%q should not be parsed as nikto output", input)
+		}
+	}
+}
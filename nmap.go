@@ -0,0 +1,143 @@
+package gocdp
+
+import (
+	"encoding/xml"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	nmapEnumRegex   *regexp.Regexp = regexp.MustCompile(`^\s*(?P<path>/[^:\s]*):\s*(?P<note>.*)$`)
+	nmapStatusRegex *regexp.Regexp = regexp.MustCompile(`\(([0-9]{3})(?:\s[^)]*)?\)\s*$`)
+)
+
+type nmapOutput struct {
+	XMLName xml.Name   `xml:"nmaprun"`
+	Scanner string     `xml:"scanner,attr"`
+	Hosts   []nmapHost `xml:"host"`
+}
+type nmapHost struct {
+	Addresses []struct {
+		Addr     string `xml:"addr,attr"`
+		AddrType string `xml:"addrtype,attr"`
+	} `xml:"address"`
+	Hostnames []struct {
+		Name string `xml:"name,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"hostnames>hostname"`
+	Ports []nmapPort `xml:"ports>port"`
+}
+type nmapPort struct {
+	PortID  string `xml:"portid,attr"`
+	Service struct {
+		Name   string `xml:"name,attr"`
+		Tunnel string `xml:"tunnel,attr"`
+	} `xml:"service"`
+	Scripts []struct {
+		ID     string `xml:"id,attr"`
+		Output string `xml:"output,attr"`
+	} `xml:"script"`
+}
+
+func (h nmapHost) host() string {
+	for _, hostname := range h.Hostnames {
+		if hostname.Type == "user" {
+			return hostname.Name
+		}
+	}
+
+	if len(h.Hostnames) != 0 {
+		return h.Hostnames[0].Name
+	}
+
+	for _, address := range h.Addresses {
+		if address.AddrType == "ipv4" || address.AddrType == "ipv6" {
+			return address.Addr
+		}
+	}
+
+	return ""
+}
+
+func (p nmapPort) scheme() string {
+	name := strings.ToLower(p.Service.Name)
+	if p.Service.Tunnel == "ssl" || name == "https" || strings.HasPrefix(name, "ssl/") || strings.HasSuffix(name, "-ssl") {
+		return "https"
+	}
+	return "http"
+}
+
+// NmapParser parses the paths found by the http-enum NSE script from Nmap XML output i.e. nmap -oX
+type NmapParser struct {
+}
+
+func (NmapParser) Parse(input string) (CDResults, error) {
+	var output nmapOutput
+	err := xml.Unmarshal([]byte(input), &output)
+	if err != nil {
+		return nil, err
+	}
+
+	var results CDResults
+	for _, host := range output.Hosts {
+		for _, port := range host.Ports {
+			for _, script := range port.Scripts {
+				if script.ID != "http-enum" {
+					continue
+				}
+
+				for _, line := range strings.Split(script.Output, "\n") {
+					match := nmapEnumRegex.FindStringSubmatch(line)
+					if len(match) == 0 {
+						continue
+					}
+
+					namedMatches := make(map[string]string)
+					for j, name := range nmapEnumRegex.SubexpNames() {
+						if j != 0 && name != "" {
+							namedMatches[name] = match[j]
+						}
+					}
+
+					status := StatusUnknown
+					note := strings.TrimSpace(namedMatches["note"])
+					if statusMatch := nmapStatusRegex.FindStringSubmatch(note); len(statusMatch) == 2 {
+						status, _ = strconv.Atoi(statusMatch[1])
+					}
+
+					results = append(results, CDResult{
+						Url:           buildURL(port.scheme(), host.host(), port.PortID, namedMatches["path"]),
+						Status:        status,
+						ContentLength: LengthUnknown,
+						Extra: map[string]interface{}{
+							"note": note,
+						},
+						source: newTextRecord(line, len(results)),
+					})
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (NmapParser) CanParse(input string) bool {
+	var output nmapOutput
+	err := xml.Unmarshal([]byte(input), &output)
+	if err != nil {
+		return false
+	}
+
+	return output.Scanner == "nmap"
+}
+
+func (NmapParser) CanTransform() bool {
+	return false
+}
+
+func (NmapParser) Transform(input string, records []Record) (string, error) {
+	return "", errors.New("nmap output cannot be transformed")
+}
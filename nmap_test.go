@@ -0,0 +1,72 @@
+package gocdp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const nmapInput = `<?xml version="1.0" encoding="UTF-8"?>
+<nmaprun scanner="nmap" args="nmap -sV --script http-enum -oX nmap.xml 10.0.0.1" start="1">
+<host><status state="up"/>
+<address addr="10.0.0.1" addrtype="ipv4"/>
+<hostnames><hostname name="web.example.com" type="user"/></hostnames>
+<ports>
+<port protocol="tcp" portid="80"><state state="open"/><service name="http"/>
+<script id="http-enum" output="&#xa;  /admin/: Possible admin folder (401 Unauthorized)&#xa;  /robots.txt: Robots file&#xa;"/></port>
+<port protocol="tcp" portid="8443"><state state="open"/><service name="http" tunnel="ssl"/>
+<script id="http-enum" output="&#xa;  /manager/html: Apache Tomcat (401 Unauthorized)&#xa;"/></port>
+<port protocol="tcp" portid="22"><state state="open"/><service name="ssh"/>
+<script id="ssh-hostkey" output="&#xa;  /not/a/path: ignored&#xa;"/></port>
+</ports></host>
+</nmaprun>
+`
+
+func TestNmapParser(t *testing.T) {
+	parser := NmapParser{}
+	if !parser.CanParse(nmapInput) {
+		t.Fatal("expected the nmap output to be parseable")
+	}
+
+	results, err := parser.Parse(nmapInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"http://web.example.com/admin/|401|-1||",
+		"http://web.example.com/robots.txt|-1|-1||",
+		"https://web.example.com:8443/manager/html|401|-1||",
+	}
+	if fields := resultFields(results); !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected %v, got %v", expected, fields)
+	}
+
+	if note := results[0].Extra["note"]; note != "Possible admin folder (401 Unauthorized)" {
+		t.Errorf("unexpected note %q", note)
+	}
+}
+
+func TestNmapParserCanParse(t *testing.T) {
+	inputs := []string{
+		`<?xml version="1.0"?><nmaprun scanner="masscan"></nmaprun>`,
+		`<niktoscans></niktoscans>`,
+		"http://web.example.com/admin/",
+	}
+
+	for _, input := range inputs {
+		if (NmapParser{}).CanParse(input) {
+			t.Errorf("%q should not be parsed as nmap output", input)
+		}
+	}
+}
+
+func TestNmapParserCannotTransform(t *testing.T) {
+	if (NmapParser{}).CanTransform() {
+		t.Error("nmap output should not be transformable")
+	}
+
+	if _, err := SmartTrim(strings.NewReader(nmapInput), []TrimOption{}); err == nil {
+		t.Error("expected trimming nmap output to fail")
+	}
+}
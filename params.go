@@ -0,0 +1,353 @@
+package gocdp
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/orderedmap"
+)
+
+const (
+	ParamLocationQuery  = "query"
+	ParamLocationBody   = "body"
+	ParamLocationHeader = "header"
+)
+
+var (
+	x8StandardRegex *regexp.Regexp = regexp.MustCompile(`^(?P<method>[A-Z]+)\s+(?P<url>https?://[^\s]+)(?:\s+\((?P<status>[0-9]+)\))?(?:\s+\[(?P<length>[0-9]+)\])?\s+%\s+(?P<params>.+)$`)
+)
+
+// paramLocation returns the location of the parameters based on the request method
+func paramLocation(method string) string {
+	if strings.EqualFold(method, "GET") {
+		return ParamLocationQuery
+	}
+	return ParamLocationBody
+}
+
+type arjunResult struct {
+	Method string   `json:"method"`
+	Params []string `json:"params"`
+}
+
+// ArjunParser parses the JSON output of Arjun i.e. arjun -oJ
+type ArjunParser struct {
+}
+
+func (ArjunParser) parse(input string) (*orderedmap.OrderedMap, error) {
+	output := orderedmap.New()
+	err := json.Unmarshal([]byte(input), output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func (p ArjunParser) Parse(input string) (CDResults, error) {
+	output, err := p.parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var results CDResults
+	for _, u := range output.Keys() {
+		value, _ := output.Get(u)
+
+		bytes, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		var result arjunResult
+		err = json.Unmarshal(bytes, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, CDResult{
+			Url:           u,
+			Status:        StatusUnknown,
+			ContentLength: LengthUnknown,
+			Params:        result.Params,
+			ParamLocation: paramLocation(result.Method),
+		})
+	}
+
+	return results, nil
+}
+
+func (p ArjunParser) CanParse(input string) bool {
+	output, err := p.parse(input)
+	if err != nil || len(output.Keys()) == 0 {
+		return false
+	}
+
+	for _, key := range output.Keys() {
+		if !strings.HasPrefix(key, "http://") && !strings.HasPrefix(key, "https://") {
+			return false
+		}
+
+		value, _ := output.Get(key)
+		m, ok := value.(orderedmap.OrderedMap)
+		if !ok {
+			return false
+		}
+
+		if _, ok := m.Get("params"); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (ArjunParser) CanTransform() bool {
+	return false
+}
+
+func (ArjunParser) Transform(input string, records []Record) (string, error) {
+	return "", errors.New("arjun output cannot be transformed")
+}
+
+type x8Result struct {
+	Method         string          `json:"method"`
+	URL            string          `json:"url"`
+	Status         int             `json:"status"`
+	Size           int             `json:"size"`
+	InjectionPlace string          `json:"injection_place"`
+	FoundParams    json.RawMessage `json:"found_params"`
+}
+
+func (r x8Result) params() []string {
+	var names []string
+	if err := json.Unmarshal(r.FoundParams, &names); err == nil {
+		return names
+	}
+	names = nil
+
+	var params []struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(r.FoundParams, &params)
+
+	for _, param := range params {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
+func (r x8Result) location() string {
+	switch strings.ToLower(r.InjectionPlace) {
+	case "body":
+		return ParamLocationBody
+	case "headers", "headervalue":
+		return ParamLocationHeader
+	case "path":
+		return ParamLocationQuery
+	default:
+		return paramLocation(r.Method)
+	}
+}
+
+// X8Parser parses the JSON and standard output of x8 i.e. x8 -O json|standard
+type X8Parser struct {
+}
+
+// cleanURL removes the parameter placeholder x8 adds to the URL
+func (X8Parser) cleanURL(u string) string {
+	u = strings.ReplaceAll(u, "%s", "")
+	return strings.TrimRight(u, "?&")
+}
+
+func (X8Parser) isJSONResult(input string) bool {
+	var output []x8Result
+	err := json.Unmarshal([]byte(input), &output)
+	if err != nil || len(output) == 0 {
+		return false
+	}
+
+	return output[0].URL != "" && output[0].FoundParams != nil
+}
+
+func (X8Parser) isStandardResult(input string) bool {
+	for _, line := range strings.Split(input, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if !x8StandardRegex.MatchString(line) {
+			return false
+		}
+	}
+
+	return strings.TrimSpace(input) != ""
+}
+
+func (p X8Parser) parseJSON(input string) (CDResults, error) {
+	var output []x8Result
+	err := json.Unmarshal([]byte(input), &output)
+	if err != nil {
+		return nil, err
+	}
+
+	var results CDResults
+	for _, result := range output {
+		results = append(results, CDResult{
+			Url:           p.cleanURL(result.URL),
+			Status:        result.Status,
+			ContentLength: result.Size,
+			Params:        result.params(),
+			ParamLocation: result.location(),
+		})
+	}
+
+	return results, nil
+}
+
+func (p X8Parser) parseStandard(input string) (CDResults, error) {
+	var results CDResults
+	for _, line := range strings.Split(input, "\n") {
+		match := x8StandardRegex.FindStringSubmatch(line)
+		if len(match) == 0 {
+			continue
+		}
+
+		namedMatches := make(map[string]string)
+		for j, name := range x8StandardRegex.SubexpNames() {
+			if j != 0 && name != "" {
+				namedMatches[name] = match[j]
+			}
+		}
+
+		status := StatusUnknown
+		if namedMatches["status"] != "" {
+			status, _ = strconv.Atoi(namedMatches["status"])
+		}
+
+		length := LengthUnknown
+		if namedMatches["length"] != "" {
+			length, _ = strconv.Atoi(namedMatches["length"])
+		}
+
+		var params []string
+		for _, param := range strings.Split(namedMatches["params"], ",") {
+			param = strings.TrimSpace(param)
+			if param != "" {
+				params = append(params, param)
+			}
+		}
+
+		results = append(results, CDResult{
+			Url:           p.cleanURL(namedMatches["url"]),
+			Status:        status,
+			ContentLength: length,
+			Params:        params,
+			ParamLocation: paramLocation(namedMatches["method"]),
+			source:        newTextRecord(line, len(results)),
+		})
+	}
+
+	return results, nil
+}
+
+func (p X8Parser) Parse(input string) (CDResults, error) {
+	if p.isJSONResult(input) {
+		return p.parseJSON(input)
+	} else if p.isStandardResult(input) {
+		return p.parseStandard(input)
+	}
+
+	return nil, nil
+}
+
+func (p X8Parser) CanParse(input string) bool {
+	return p.isJSONResult(input) || p.isStandardResult(input)
+}
+
+func (X8Parser) CanTransform() bool {
+	return false
+}
+
+func (X8Parser) Transform(input string, records []Record) (string, error) {
+	return "", errors.New("x8 output cannot be transformed")
+}
+
+// GroupByParam groups the results by the parameter names e.g. all results with the parameter "id" are grouped.
+// A result with multiple parameters is in multiple groups
+func (results CDResults) GroupByParam() map[string][]CDResult {
+	grouped := make(map[string][]CDResult)
+	for _, result := range results {
+		for _, param := range result.Params {
+			grouped[param] = append(grouped[param], result)
+		}
+	}
+
+	for _, group := range grouped {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].Url < group[j].Url
+		})
+	}
+	return grouped
+}
+
+// MergeParams merges the results with parameters into the results without parameters for the same URL, ignoring the query string.
+// Parameter results without a matching result are kept as they are
+func (results CDResults) MergeParams() CDResults {
+	var merged CDResults
+
+	index := make(map[string][]int)
+	for _, result := range results {
+		if len(result.Params) != 0 {
+			continue
+		}
+
+		key := urlWithoutQuery(result.Url)
+		index[key] = append(index[key], len(merged))
+		merged = append(merged, result)
+	}
+
+	for _, result := range results {
+		if len(result.Params) == 0 {
+			continue
+		}
+
+		found := false
+		for _, i := range index[urlWithoutQuery(result.Url)] {
+			// Parameters from different locations are kept as separate results
+			if merged[i].ParamLocation != "" && merged[i].ParamLocation != result.ParamLocation {
+				continue
+			}
+
+			merged[i] = merged[i].withParams(result.Params, result.ParamLocation)
+			found = true
+		}
+
+		if !found {
+			merged = append(merged, result)
+		}
+	}
+
+	return merged
+}
+
+// withParams returns a copy of the result with the parameters added
+func (result CDResult) withParams(params []string, location string) CDResult {
+	seen := make(map[string]bool)
+
+	var all []string
+	for _, param := range append(append([]string{}, result.Params...), params...) {
+		if !seen[param] {
+			seen[param] = true
+			all = append(all, param)
+		}
+	}
+
+	result.Params = all
+	result.ParamLocation = location
+	return result
+}
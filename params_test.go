@@ -0,0 +1,141 @@
+package gocdp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const arjunInput = `{
+    "https://web.example.com/api": {"headers": {"User-Agent": "x"}, "method": "GET", "params": ["id", "debug"]},
+    "https://web.example.com/login": {"headers": {}, "method": "POST", "params": ["redirect"]}
+}`
+
+const x8JSONInput = `[{"method":"GET","url":"https://web.example.com/api?%s","status":200,"size":120,"found_params":[{"name":"debug","value":null,"diffs":"","status":200,"size":300,"reason_kind":"Code"},{"name":"admin"}],"injection_place":"Path"}]`
+
+const x8StandardInput = `GET https://web.example.com/search?%s % q, page
+POST https://web.example.com/login (200) [512] % user
+`
+
+// paramFields formats the URL, status, length and parameters of the results for comparison
+func paramFields(results CDResults) []string {
+	fields := []string{}
+	for _, r := range results {
+		fields = append(fields, fmt.Sprintf("%s|%d|%d|%s|%s", r.Url, r.Status, r.ContentLength, strings.Join(r.Params, ","), r.ParamLocation))
+	}
+	return fields
+}
+
+func TestParamParsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   Parser
+		input    string
+		expected []string
+	}{
+		{
+			name:   "arjun",
+			parser: ArjunParser{},
+			input:  arjunInput,
+			expected: []string{
+				"https://web.example.com/api|-1|-1|id,debug|query",
+				"https://web.example.com/login|-1|-1|redirect|body",
+			},
+		},
+		{
+			name:   "x8 json",
+			parser: X8Parser{},
+			input:  x8JSONInput,
+			expected: []string{
+				"https://web.example.com/api|200|120|debug,admin|query",
+			},
+		},
+		{
+			name:   "x8 standard",
+			parser: X8Parser{},
+			input:  x8StandardInput,
+			expected: []string{
+				"https://web.example.com/search|-1|-1|q,page|query",
+				"https://web.example.com/login|200|512|user|body",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !test.parser.CanParse(test.input) {
+				t.Fatal("expected the output to be parseable")
+			}
+
+			results, err := test.parser.Parse(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fields := paramFields(results); !reflect.DeepEqual(fields, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, fields)
+			}
+		})
+	}
+}
+
+func TestParamParsersCanParse(t *testing.T) {
+	inputs := []string{
+		`{"api": {"method": "GET", "params": ["id"]}}`,
+		`{"https://web.example.com/api": {"method": "GET"}}`,
+		`{}`,
+		`[{"url":"https://web.example.com/api","status":200}]`,
+		"GET https://web.example.com/search\n",
+		"https://web.example.com/search % q\n",
+	}
+
+	for _, input := range inputs {
+		if (ArjunParser{}).CanParse(input) || (X8Parser{}).CanParse(input) {
+			t.Errorf("%q should not be parsed as parameter output", input)
+		}
+	}
+}
+
+func TestGroupByParam(t *testing.T) {
+	results := CDResults{
+		{Url: "https://web.example.com/b", Params: []string{"id", "debug"}},
+		{Url: "https://web.example.com/a", Params: []string{"id"}},
+		{Url: "https://web.example.com/c"},
+	}
+
+	grouped := results.GroupByParam()
+	if len(grouped) != 2 {
+		t.Fatalf("expected 2 groups, got %v", grouped)
+	}
+
+	var urls []string
+	for _, result := range grouped["id"] {
+		urls = append(urls, result.Url)
+	}
+	if expected := []string{"https://web.example.com/a", "https://web.example.com/b"}; !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected %v, got %v", expected, urls)
+	}
+	if len(grouped["debug"]) != 1 {
+		t.Errorf("expected 1 debug result, got %d", len(grouped["debug"]))
+	}
+}
+
+func TestMergeParams(t *testing.T) {
+	results := CDResults{
+		{Url: "https://web.example.com/api", Status: 200, ContentLength: 10},
+		{Url: "https://web.example.com/api?x=1", Status: StatusUnknown, Params: []string{"id", "debug"}, ParamLocation: ParamLocationQuery},
+		{Url: "https://web.example.com/api", Status: StatusUnknown, Params: []string{"debug", "admin"}, ParamLocation: ParamLocationQuery},
+		{Url: "https://web.example.com/api", Status: StatusUnknown, Params: []string{"token"}, ParamLocation: ParamLocationBody},
+		{Url: "https://web.example.com/other", Status: StatusUnknown, Params: []string{"q"}, ParamLocation: ParamLocationQuery},
+	}
+
+	expected := []string{
+		"https://web.example.com/api|200|10|id,debug,admin|query",
+		"https://web.example.com/api|-1|0|token|body",
+		"https://web.example.com/other|-1|0|q|query",
+	}
+	if fields := paramFields(results.MergeParams()); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+}
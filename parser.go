@@ -1,6 +1,7 @@
 package gocdp
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -12,60 +13,221 @@ const (
 	AndOperator
 )
 
+// MatchMode is how the patterns of the URL and redirect filters are matched. The modes can be combined e.g. MatchLiteral | MatchIgnoreCase
+type MatchMode int
+
+const (
+	// MatchRegex matches the patterns as regular expressions
+	MatchRegex MatchMode = 0
+	// MatchLiteral matches the results containing the patterns as is
+	MatchLiteral MatchMode = 1 << iota
+	// MatchIgnoreCase matches the patterns case-insensitively
+	MatchIgnoreCase
+)
+
+// compilePatterns compiles the patterns with the match mode
+func compilePatterns(name string, mode MatchMode, patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		expr := pattern
+		if mode&MatchLiteral != 0 {
+			expr = regexp.QuoteMeta(expr)
+		}
+		if mode&MatchIgnoreCase != 0 {
+			expr = "(?i)" + expr
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s filter '%s': %w", name, pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func matchAny(regexes []*regexp.Regexp, s string) bool {
+	for _, re := range regexes {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
 type Parser interface {
 	Parse(input string) (CDResults, error)
 	CanParse(input string) bool
 	CanTransform() bool
-	Transform(input string, filtered []interface{}) (string, error)
+	// Transform rewrites the input keeping only the records, which must be the records of results parsed from the input
+	Transform(input string, records []Record) (string, error)
+}
+
+type trimFilter struct {
+	name  string
+	match func(CDResult) (bool, error)
+}
+
+// transformLines removes the result lines which were not kept from the input, keeping every other line
+// such as banners and status messages in their original position
+func transformLines(input string, records []Record, isResult func(line string) bool) (string, error) {
+	filtered, err := textRecords(records)
+	if err != nil {
+		return "", err
+	}
+
+	kept := make(map[string]int)
+	for _, line := range filtered {
+		kept[line] += 1
+	}
+
+	var lines []string
+	for _, line := range strings.Split(input, "\n") {
+		// The result lines were read without the carriage return
+		result := strings.TrimSuffix(line, "\r")
+		if isResult(result) {
+			if kept[result] == 0 {
+				continue
+			}
+			kept[result] -= 1
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n"), nil
 }
 
 type TrimOptions struct {
 	maxResults int
-	filters    []func(CDResult) bool
+	maxKey     KeyFunc
+	sampler    Sampler
+	auto       *AutoCalibration
+	filters    []trimFilter
 	operator   TrimOperator
+	keep       bool
+	dryRun     bool
+	verify     bool
+	// allOrNothing is only used by SmartTrimFiles
+	allOrNothing bool
+	report       func(*TrimReport)
+	err          error
+	// file is the file being trimmed, if any, and files all the files trimmed by SmartTrimFiles
+	file  string
+	files []string
+
+	backupSuffix string
+	outputDir    string
+	outputSuffix string
+
+	responses    responseAction
+	responsesDir string
 }
 
 type TrimOption func(*TrimOptions)
 
+// setErr keeps the first error from the options, which is returned when trimming
+func (o *TrimOptions) setErr(err error) {
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+func (o *TrimOptions) addFilter(name string, match func(CDResult) bool) {
+	o.addFilterErr(name, func(c CDResult) (bool, error) {
+		return match(c), nil
+	})
+}
+
+func (o *TrimOptions) addFilterErr(name string, match func(CDResult) (bool, error)) {
+	o.filters = append(o.filters, trimFilter{
+		name:  name,
+		match: match,
+	})
+}
+
+func newTrimOptions(opts []TrimOption) *TrimOptions {
+	options := &TrimOptions{
+		filters:  make([]trimFilter, 0),
+		operator: OrOperator,
+		maxKey:   KeyStatus,
+		sampler:  SampleFirst,
+		verify:   true,
+	}
+
+	for _, o := range opts {
+		o(options)
+	}
+
+	if options.allOrNothing && !options.verify {
+		options.setErr(errSkipVerifyAllOrNothing)
+	}
+
+	// The original files still use their stored responses when they are kept
+	if options.responses != keepResponses && (options.backupSuffix != "" || options.outputDir != "" || options.outputSuffix != "") {
+		options.setErr(errKeptResponses)
+	}
+	return options
+}
+
+// WithMaxResults keeps at most max results per status code. The kept results are picked by the sampler, see WithSampler
 func WithMaxResults(max int) TrimOption {
+	return WithMaxResultsBy(max, KeyStatus)
+}
+
+// WithMaxResultsBy keeps at most max results per key e.g. KeyStatusLength keeps max results for each status code and content length
+func WithMaxResultsBy(max int, key KeyFunc) TrimOption {
 	return func(o *TrimOptions) {
 		o.maxResults = max
+		o.maxKey = key
 	}
 }
 
+// WithFilterRedirect filters the results with redirect URLs matching any of the regexes.
+// Invalid regexes are returned as errors when trimming
 func WithFilterRedirect(regexes ...string) TrimOption {
+	return WithFilterRedirectMatch(MatchRegex, regexes...)
+}
+
+// WithFilterRedirectMatch filters the results with redirect URLs matching any of the patterns with the match mode
+func WithFilterRedirectMatch(mode MatchMode, patterns ...string) TrimOption {
+	regexes, err := compilePatterns("redirect", mode, patterns)
 	return func(o *TrimOptions) {
-		o.filters = append(o.filters, func(c CDResult) bool {
-			for _, re := range regexes {
-				match, _ := regexp.MatchString(re, c.Redirect)
-				if match {
-					return true
-				}
-			}
+		if err != nil {
+			o.setErr(err)
+			return
+		}
 
-			return false
+		o.addFilter("redirect", func(c CDResult) bool {
+			return matchAny(regexes, c.Redirect)
 		})
 	}
 }
 
+// WithFilterURL filters the results with URLs matching any of the regexes.
+// Invalid regexes are returned as errors when trimming
 func WithFilterURL(regexes ...string) TrimOption {
+	return WithFilterURLMatch(MatchRegex, regexes...)
+}
+
+// WithFilterURLMatch filters the results with URLs matching any of the patterns with the match mode
+func WithFilterURLMatch(mode MatchMode, patterns ...string) TrimOption {
+	regexes, err := compilePatterns("url", mode, patterns)
 	return func(o *TrimOptions) {
-		o.filters = append(o.filters, func(c CDResult) bool {
-			for _, re := range regexes {
-				match, _ := regexp.MatchString(re, c.Url)
-				if match {
-					return true
-				}
-			}
+		if err != nil {
+			o.setErr(err)
+			return
+		}
 
-			return false
+		o.addFilter("url", func(c CDResult) bool {
+			return matchAny(regexes, c.Url)
 		})
 	}
 }
 
 func WithFilterContentType(contentTypes ...string) TrimOption {
 	return func(o *TrimOptions) {
-		o.filters = append(o.filters, func(c CDResult) bool {
+		o.addFilter("content-type", func(c CDResult) bool {
 			for _, contentType := range contentTypes {
 				if strings.EqualFold(c.ContentType, contentType) {
 					return true
@@ -79,7 +241,7 @@ func WithFilterContentType(contentTypes ...string) TrimOption {
 
 func WithFilterStatus(statusCodes ...int) TrimOption {
 	return func(o *TrimOptions) {
-		o.filters = append(o.filters, func(c CDResult) bool {
+		o.addFilter("status", func(c CDResult) bool {
 			return c.IsStatus(statusCodes...)
 		})
 	}
@@ -87,7 +249,7 @@ func WithFilterStatus(statusCodes ...int) TrimOption {
 
 func WithFilterLength(lengths ...int) TrimOption {
 	return func(o *TrimOptions) {
-		o.filters = append(o.filters, func(c CDResult) bool {
+		o.addFilter("length", func(c CDResult) bool {
 			for _, length := range lengths {
 				if c.ContentLength == length {
 					return true
@@ -98,8 +260,287 @@ func WithFilterLength(lengths ...int) TrimOption {
 	}
 }
 
+// WithFilterStatusRange filters the results with the status in any of the ranges, see ParseIntRange
+func WithFilterStatusRange(ranges ...IntRange) TrimOption {
+	return func(o *TrimOptions) {
+		o.addFilter("status", func(c CDResult) bool {
+			return inIntRanges(c.Status, ranges)
+		})
+	}
+}
+
+// WithFilterLengthRange filters the results with the content length in any of the ranges, see ParseIntRange
+func WithFilterLengthRange(ranges ...IntRange) TrimOption {
+	return func(o *TrimOptions) {
+		o.addFilter("length", func(c CDResult) bool {
+			return inIntRanges(c.ContentLength, ranges)
+		})
+	}
+}
+
+// WithFilterWordsRange filters the results with the number of words in any of the ranges, see ParseIntRange
+func WithFilterWordsRange(ranges ...IntRange) TrimOption {
+	return func(o *TrimOptions) {
+		o.addFilter("words", func(c CDResult) bool {
+			return inIntRanges(c.Words, ranges)
+		})
+	}
+}
+
+// WithFilterLinesRange filters the results with the number of lines in any of the ranges, see ParseIntRange
+func WithFilterLinesRange(ranges ...IntRange) TrimOption {
+	return func(o *TrimOptions) {
+		o.addFilter("lines", func(c CDResult) bool {
+			return inIntRanges(c.Lines, ranges)
+		})
+	}
+}
+
+// WithFilterDurationRange filters the results with the duration in any of the ranges, see ParseDurationRange
+func WithFilterDurationRange(ranges ...DurationRange) TrimOption {
+	return func(o *TrimOptions) {
+		o.addFilter("duration", func(c CDResult) bool {
+			return inDurationRanges(c.Duration, ranges)
+		})
+	}
+}
+
 func WithFilterOperator(op TrimOperator) TrimOption {
 	return func(o *TrimOptions) {
 		o.operator = op
 	}
 }
+
+// WithFilterFunc filters the results for which fn returns true
+func WithFilterFunc(fn func(CDResult) bool) TrimOption {
+	return func(o *TrimOptions) {
+		o.addFilter("func", fn)
+	}
+}
+
+// WithFilterWhere filters the results matching the expression, the same as the root command's query e.g. 'error and length > 5000', see Expression
+func WithFilterWhere(expr string) TrimOption {
+	e, err := ParseExpression(expr)
+	return func(o *TrimOptions) {
+		if err != nil {
+			o.setErr(err)
+			return
+		}
+
+		o.addFilter(fmt.Sprintf("where '%s'", expr), e.Match)
+	}
+}
+
+// WithFilterAgainst filters the results whose key is also the key of any of the reference results
+// e.g. KeyURL removes the results already found by previous scans. When trimming files, the reference
+// results parsed from the file being trimmed, or from files trimmed after it by SmartTrimFiles, are ignored
+// so the first copy of a result shared by the trimmed files is kept
+func WithFilterAgainst(reference CDResults, key KeyFunc) TrimOption {
+	files := make(map[string][]string)
+	for _, result := range reference {
+		k := key(result)
+		files[k] = append(files[k], result.file)
+	}
+
+	same := make(map[[2]string]bool)
+	isSameFile := func(a string, b string) bool {
+		pair := [2]string{a, b}
+		if _, ok := same[pair]; !ok {
+			same[pair] = sameFile(a, b)
+		}
+		return same[pair]
+	}
+
+	// trimIndex returns the position of the file in the files being trimmed, or -1 if it is not trimmed
+	trimIndex := func(o *TrimOptions, file string) int {
+		for i, trimmed := range o.files {
+			if isSameFile(file, trimmed) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	return func(o *TrimOptions) {
+		o.addFilter("against", func(c CDResult) bool {
+			current := trimIndex(o, c.file)
+			for _, file := range files[key(c)] {
+				if file == "" {
+					return true
+				}
+
+				if isSameFile(file, c.file) {
+					continue
+				}
+
+				if i := trimIndex(o, file); i == -1 || i < current {
+					return true
+				}
+			}
+			return false
+		})
+	}
+}
+
+// withTrimFiles sets the files being trimmed by SmartTrimFiles, in order
+func withTrimFiles(files []string) TrimOption {
+	return func(o *TrimOptions) {
+		o.files = files
+	}
+}
+
+// WithKeepMode turns the filters into an allow list, only the results which match the filters are kept
+func WithKeepMode() TrimOption {
+	return func(o *TrimOptions) {
+		o.keep = true
+	}
+}
+
+// Not negates the filters added by the option e.g. Not(WithFilterStatus(200)) matches every result without the status code 200
+func Not(opt TrimOption) TrimOption {
+	return func(o *TrimOptions) {
+		negated := newTrimOptions([]TrimOption{opt})
+		if negated.err != nil {
+			o.setErr(negated.err)
+		}
+
+		for _, filter := range negated.filters {
+			match := filter.match
+			o.addFilterErr("not "+filter.name, func(c CDResult) (bool, error) {
+				matched, err := match(c)
+				return !matched, err
+			})
+		}
+	}
+}
+
+// WithBackup keeps a copy of each overwritten file with the suffix added e.g. ".orig". Defaults to ".orig" when the suffix is empty.
+// An existing backup is never overwritten, so after repeated trims it is still the file before the first trim
+func WithBackup(suffix string) TrimOption {
+	return func(o *TrimOptions) {
+		if suffix == "" {
+			suffix = ".orig"
+		}
+		o.backupSuffix = suffix
+	}
+}
+
+// WithOutputDir writes the trimmed files to the directory instead of overwriting the original files.
+// SmartTrimFiles fails before writing anything when files with the same name would be written to the directory
+func WithOutputDir(dir string) TrimOption {
+	return func(o *TrimOptions) {
+		o.outputDir = dir
+	}
+}
+
+// WithOutputSuffix writes the trimmed files next to the original files with the suffix added before the extension
+// e.g. ffuf.json becomes ffuf.trimmed.json with the suffix ".trimmed"
+func WithOutputSuffix(suffix string) TrimOption {
+	return func(o *TrimOptions) {
+		o.outputSuffix = suffix
+	}
+}
+
+// WithDryRun trims the files without writing anything. Useful along with WithReport
+func WithDryRun() TrimOption {
+	return func(o *TrimOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithVerify parses the transformed output with the same parser and checks it contains exactly the kept results.
+// A *VerifyError is returned otherwise and nothing is written. Trims are verified by default, see WithSkipVerify
+func WithVerify() TrimOption {
+	return func(o *TrimOptions) {
+		o.verify = true
+	}
+}
+
+// WithSkipVerify writes the transformed output without verifying it, see WithVerify. The output of a broken
+// transform is written as is, which loses results when the file is overwritten
+func WithSkipVerify() TrimOption {
+	return func(o *TrimOptions) {
+		o.verify = false
+	}
+}
+
+// WithReport calls fn with the report of each trim
+func WithReport(fn func(*TrimReport)) TrimOption {
+	return func(o *TrimOptions) {
+		o.report = fn
+	}
+}
+
+// TrimReport is the summary of a trim
+type TrimReport struct {
+	// File and OutputFile are only set when trimming files
+	File       string
+	OutputFile string
+
+	Total int
+	Kept  int
+	// Removed is the number of results removed by each filter, keyed by the filter name.
+	// Results removed by WithMaxResults are under "max", by WithAutoCalibration under "auto"
+	// and results not matched in keep mode under "keep"
+	Removed map[string]int
+	// Clusters are the clusters removed by WithAutoCalibration
+	Clusters []TrimCluster
+
+	// ResponseFiles are the stored response files of the removed results, see WithRemoveResponses
+	ResponseFiles []string
+
+	// Verified is whether the output was verified, see WithVerify and WithSkipVerify
+	Verified bool
+
+	// Input is the original input and Output the transformed output
+	Input  string
+	Output string
+}
+
+func (r *TrimReport) remove(name string) {
+	r.Removed[name] += 1
+}
+
+// removedBy returns the name of the filters which removed the result, or an empty string if the result is kept
+func (o *TrimOptions) removedBy(result CDResult) (string, error) {
+	matched, err := o.match(result)
+	if err != nil || !o.keep {
+		return matched, err
+	}
+
+	if matched == "" && len(o.filters) != 0 {
+		return "keep", nil
+	}
+	return "", nil
+}
+
+// match returns the name of the filters the result matched, or an empty string if it did not match
+func (o *TrimOptions) match(result CDResult) (string, error) {
+	if len(o.filters) == 0 {
+		return "", nil
+	}
+
+	if o.operator == AndOperator {
+		var names []string
+		for _, filter := range o.filters {
+			matched, err := filter.match(result)
+			if err != nil || !matched {
+				return "", err
+			}
+			names = append(names, filter.name)
+		}
+		return strings.Join(names, " and "), nil
+	}
+
+	for _, filter := range o.filters {
+		matched, err := filter.match(result)
+		if err != nil {
+			return "", err
+		}
+
+		if matched {
+			return filter.name, nil
+		}
+	}
+	return "", nil
+}
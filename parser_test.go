@@ -0,0 +1,303 @@
+package gocdp
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var trimResults = CDResults{
+	{Url: "http://t/api/users", Status: 200, ContentLength: 10},
+	{Url: "http://t/api/admin", Status: 403, ContentLength: 20},
+	{Url: "http://t/old", Status: 301, ContentLength: 0, Redirect: "http://t/old/"},
+	{Url: "http://t/api/v1", Status: 301, ContentLength: 0, Redirect: "http://t/api/v1/"},
+	{Url: "http://t/missing", Status: 404, ContentLength: 30},
+}
+
+// trimURLs trims the results written as ffuf JSON and returns the URLs of the kept results
+func trimURLs(t *testing.T, results CDResults, opts ...TrimOption) []string {
+	t.Helper()
+
+	input, err := RenderFfufJSON(results, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := SmartTrim(strings.NewReader(input), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept, err := SmartParse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	urls := []string{}
+	for _, result := range kept {
+		urls = append(urls, result.Url)
+	}
+	return urls
+}
+
+func TestTrimFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []TrimOption
+		expected []string
+	}{
+		{
+			name:     "no filters",
+			opts:     nil,
+			expected: []string{"http://t/api/users", "http://t/api/admin", "http://t/old", "http://t/api/v1", "http://t/missing"},
+		},
+		{
+			name:     "or",
+			opts:     []TrimOption{WithFilterStatus(403, 404), WithFilterURL("/old$")},
+			expected: []string{"http://t/api/users", "http://t/api/v1"},
+		},
+		{
+			name:     "and",
+			opts:     []TrimOption{WithFilterOperator(AndOperator), WithFilterStatus(301), WithFilterURL("/api/")},
+			expected: []string{"http://t/api/users", "http://t/api/admin", "http://t/old", "http://t/missing"},
+		},
+		{
+			name:     "keep",
+			opts:     []TrimOption{WithKeepMode(), WithFilterStatus(200, 301)},
+			expected: []string{"http://t/api/users", "http://t/old", "http://t/api/v1"},
+		},
+		{
+			name:     "keep and",
+			opts:     []TrimOption{WithKeepMode(), WithFilterOperator(AndOperator), WithFilterStatus(200, 301), WithFilterURL("/api/")},
+			expected: []string{"http://t/api/users", "http://t/api/v1"},
+		},
+		{
+			name:     "keep without filters",
+			opts:     []TrimOption{WithKeepMode()},
+			expected: []string{"http://t/api/users", "http://t/api/admin", "http://t/old", "http://t/api/v1", "http://t/missing"},
+		},
+		{
+			name:     "not",
+			opts:     []TrimOption{Not(WithFilterURL("/api/"))},
+			expected: []string{"http://t/api/users", "http://t/api/admin", "http://t/api/v1"},
+		},
+		{
+			name:     "not with several values",
+			opts:     []TrimOption{Not(WithFilterStatus(200, 403))},
+			expected: []string{"http://t/api/users", "http://t/api/admin"},
+		},
+		{
+			name:     "keep not",
+			opts:     []TrimOption{WithKeepMode(), Not(WithFilterStatus(404))},
+			expected: []string{"http://t/api/users", "http://t/api/admin", "http://t/old", "http://t/api/v1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if urls := trimURLs(t, trimResults, test.opts...); !reflect.DeepEqual(urls, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, urls)
+			}
+		})
+	}
+}
+
+func TestTrimReportFilterNames(t *testing.T) {
+	input, err := RenderFfufJSON(trimResults, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report *TrimReport
+	opts := []TrimOption{
+		WithKeepMode(),
+		Not(WithFilterStatus(404)),
+		WithReport(func(r *TrimReport) {
+			report = r
+		}),
+	}
+	if _, err := SmartTrim(strings.NewReader(input), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := map[string]int{"keep": 1}; !reflect.DeepEqual(report.Removed, expected) {
+		t.Errorf("expected %v, got %v", expected, report.Removed)
+	}
+}
+
+func TestNotPropagatesErrors(t *testing.T) {
+	_, err := SmartTrim(strings.NewReader(""), []TrimOption{Not(WithFilterURL("("))})
+	if err == nil {
+		t.Error("expected the invalid pattern to be reported")
+	}
+}
+
+func TestMatchModes(t *testing.T) {
+	results := CDResults{
+		{Url: "http://t/static/app.js", Status: 200},
+		{Url: "http://t/STATIC/logo.png", Status: 200},
+		{Url: "http://t/static", Status: 301, Redirect: "http://t/static/"},
+		{Url: "http://t/api", Status: 200},
+	}
+
+	tests := []struct {
+		name     string
+		opt      TrimOption
+		expected []string
+	}{
+		{"regex", WithFilterURL("/static/?"), []string{"http://t/STATIC/logo.png", "http://t/api"}},
+		{"literal", WithFilterURLMatch(MatchLiteral, "/static/?"), []string{"http://t/static/app.js", "http://t/STATIC/logo.png", "http://t/static", "http://t/api"}},
+		{"ignore case", WithFilterURLMatch(MatchIgnoreCase, "/static"), []string{"http://t/api"}},
+		{"literal ignore case", WithFilterURLMatch(MatchLiteral|MatchIgnoreCase, "/STATIC/"), []string{"http://t/static", "http://t/api"}},
+		{"redirect", WithFilterRedirectMatch(MatchLiteral, "/static/"), []string{"http://t/static/app.js", "http://t/STATIC/logo.png", "http://t/api"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if urls := trimURLs(t, results, test.opt); !reflect.DeepEqual(urls, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, urls)
+			}
+		})
+	}
+}
+
+func TestInvalidPatterns(t *testing.T) {
+	tests := []struct {
+		opt      TrimOption
+		expected string
+	}{
+		{WithFilterURL("/ok", "(unclosed"), "invalid url filter '(unclosed'"},
+		{WithFilterRedirect("[a-"), "invalid redirect filter '[a-'"},
+		{WithFilterURLMatch(MatchIgnoreCase, "*"), "invalid url filter '*'"},
+	}
+
+	for _, test := range tests {
+		_, err := SmartTrim(strings.NewReader(""), []TrimOption{test.opt})
+		if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+			t.Errorf("expected an error starting with %q, got %v", test.expected, err)
+		}
+	}
+
+	// Literal patterns are always valid
+	if _, err := compilePatterns("url", MatchLiteral, []string{"(unclosed", "[a-"}); err != nil {
+		t.Errorf("expected literal patterns to compile, got %v", err)
+	}
+}
+
+func TestWithFilterAgainst(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.json")
+	file := filepath.Join(dir, "new.json")
+	writeFfufFile(t, old, "http://t/a", "http://t/b?x=1")
+	writeFfufFile(t, file, "http://t/a", "http://t/b", "http://t/c")
+
+	// The results of the file being trimmed are ignored, even through another path to it
+	reference, err := SmartParseFiles([]string{old, filepath.Join(dir, ".", "new.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SmartTrimFile(file, []TrimOption{WithFilterAgainst(reference, KeyURLPath)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if urls, expected := fileURLs(t, file), []string{"http://t/c"}; !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected %v, got %v", expected, urls)
+	}
+}
+
+func TestWithFilterAgainstResults(t *testing.T) {
+	// Reference results which were not parsed from a file always match
+	reference := CDResults{{Url: "http://t/old"}, {Url: "http://t/api/admin"}}
+
+	urls := trimURLs(t, trimResults, WithFilterAgainst(reference, KeyURL))
+	if expected := []string{"http://t/api/users", "http://t/api/v1", "http://t/missing"}; !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected %v, got %v", expected, urls)
+	}
+}
+
+func TestWithFilterAgainstTrimmedFiles(t *testing.T) {
+	dir := t.TempDir()
+	x := filepath.Join(dir, "x.json")
+	y := filepath.Join(dir, "y.json")
+	writeFfufFile(t, x, "http://a/shared", "http://a/x")
+	writeFfufFile(t, y, "http://a/shared", "http://a/y")
+
+	reference, err := SmartParseFiles([]string{y, x})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the copy in the first trimmed file is kept, in both modes
+	for _, opts := range [][]TrimOption{{}, {WithAllOrNothing()}} {
+		writeFfufFile(t, x, "http://a/shared", "http://a/x")
+		writeFfufFile(t, y, "http://a/shared", "http://a/y")
+
+		err = SmartTrimFiles([]string{x, y}, append(opts, WithFilterAgainst(reference, KeyURL)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if urls, expected := fileURLs(t, x), []string{"http://a/shared", "http://a/x"}; !reflect.DeepEqual(urls, expected) {
+			t.Errorf("%s: expected %v, got %v", x, expected, urls)
+		}
+		if urls, expected := fileURLs(t, y), []string{"http://a/y"}; !reflect.DeepEqual(urls, expected) {
+			t.Errorf("%s: expected %v, got %v", y, expected, urls)
+		}
+	}
+}
+
+func TestWithFilterFuncAndWhere(t *testing.T) {
+	tests := []struct {
+		name     string
+		opt      TrimOption
+		expected []string
+	}{
+		{
+			name: "func",
+			opt: WithFilterFunc(func(c CDResult) bool {
+				return c.IsRedirect()
+			}),
+			expected: []string{"http://t/api/users", "http://t/api/admin", "http://t/missing"},
+		},
+		{
+			name: "not func",
+			opt: Not(WithFilterFunc(func(c CDResult) bool {
+				return c.IsRedirect()
+			})),
+			expected: []string{"http://t/old", "http://t/api/v1"},
+		},
+		{
+			name:     "where",
+			opt:      WithFilterWhere(`status in [301, 404] or url =~ "admin$"`),
+			expected: []string{"http://t/api/users"},
+		},
+		{
+			name:     "not where",
+			opt:      Not(WithFilterWhere(`status in [301, 404] or url =~ "admin$"`)),
+			expected: []string{"http://t/api/admin", "http://t/old", "http://t/api/v1", "http://t/missing"},
+		},
+		{
+			name:     "where without matches",
+			opt:      WithFilterWhere("length > 1000"),
+			expected: []string{"http://t/api/users", "http://t/api/admin", "http://t/old", "http://t/api/v1", "http://t/missing"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if urls := trimURLs(t, trimResults, test.opt); !reflect.DeepEqual(urls, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, urls)
+			}
+		})
+	}
+}
+
+func TestWithFilterWhereInvalid(t *testing.T) {
+	_, err := SmartTrim(strings.NewReader(""), []TrimOption{Not(WithFilterWhere("status >"))})
+	if err == nil || !strings.Contains(err.Error(), "invalid expression") {
+		t.Errorf("expected the invalid expression to be reported, got %v", err)
+	}
+}
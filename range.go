@@ -0,0 +1,202 @@
+package gocdp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntRange is an inclusive range of integers. See ParseIntRange for the syntax
+type IntRange struct {
+	Min int
+	Max int
+}
+
+// ParseIntRange parses a single value "404", a range "500-599", an open range "500-",
+// or a value with a tolerance, either absolute "4242~10" or relative "4242~2%"
+func ParseIntRange(s string) (IntRange, error) {
+	s = strings.TrimSpace(s)
+
+	if value, err := strconv.Atoi(s); err == nil {
+		return IntRange{Min: value, Max: value}, nil
+	}
+
+	if parts := strings.SplitN(s, "~", 2); len(parts) == 2 {
+		value, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return IntRange{}, fmt.Errorf("invalid range '%s'", s)
+		}
+
+		tolerance, err := parseTolerance(strings.TrimSpace(parts[1]), float64(value))
+		if err != nil || tolerance >= math.MaxInt {
+			return IntRange{}, fmt.Errorf("invalid range '%s'", s)
+		}
+
+		delta := int(math.Round(tolerance))
+		r := IntRange{Min: value - delta, Max: value + delta}
+		// The range is inverted when it overflows
+		if r.Min > r.Max {
+			return IntRange{}, fmt.Errorf("invalid range '%s'", s)
+		}
+		return r, nil
+	}
+
+	if i := strings.Index(s, "-"); i != -1 {
+		r := IntRange{Max: math.MaxInt}
+
+		var err error
+		r.Min, err = strconv.Atoi(strings.TrimSpace(s[:i]))
+		if max := strings.TrimSpace(s[i+1:]); err == nil && max != "" {
+			r.Max, err = strconv.Atoi(max)
+		}
+
+		if err != nil || r.Min > r.Max {
+			return IntRange{}, fmt.Errorf("invalid range '%s'", s)
+		}
+		return r, nil
+	}
+
+	return IntRange{}, fmt.Errorf("invalid range '%s'", s)
+}
+
+// ParseIntRanges parses comma separated ranges e.g. "200-299,301,4242~2%"
+func ParseIntRanges(s string) ([]IntRange, error) {
+	var ranges []IntRange
+	for _, part := range strings.Split(s, ",") {
+		r, err := ParseIntRange(part)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func (r IntRange) Contains(value int) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+func (r IntRange) String() string {
+	switch {
+	case r.Min == r.Max:
+		return strconv.Itoa(r.Min)
+	case r.Max == math.MaxInt:
+		return fmt.Sprintf("%d-", r.Min)
+	default:
+		return fmt.Sprintf("%d-%d", r.Min, r.Max)
+	}
+}
+
+// DurationRange is an inclusive range of durations. The syntax is the same as IntRange with durations
+// e.g. "100ms-2s" or "500ms~10%"
+type DurationRange struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// ParseDurationRange parses a duration range, see DurationRange
+func ParseDurationRange(s string) (DurationRange, error) {
+	s = strings.TrimSpace(s)
+
+	if value, err := time.ParseDuration(s); err == nil {
+		return DurationRange{Min: value, Max: value}, nil
+	}
+
+	if parts := strings.SplitN(s, "~", 2); len(parts) == 2 {
+		value, err := time.ParseDuration(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return DurationRange{}, fmt.Errorf("invalid duration range '%s'", s)
+		}
+
+		tolerance := strings.TrimSpace(parts[1])
+		var delta time.Duration
+		if strings.HasSuffix(tolerance, "%") {
+			var t float64
+			t, err = parseTolerance(tolerance, float64(value))
+			if err == nil && t >= math.MaxInt64 {
+				err = fmt.Errorf("invalid tolerance '%s'", tolerance)
+			}
+			delta = time.Duration(t)
+		} else {
+			delta, err = time.ParseDuration(tolerance)
+		}
+
+		r := DurationRange{Min: value - delta, Max: value + delta}
+		// The range is inverted when the tolerance is negative or it overflows
+		if err != nil || r.Min > r.Max {
+			return DurationRange{}, fmt.Errorf("invalid duration range '%s'", s)
+		}
+		return r, nil
+	}
+
+	if i := strings.Index(s, "-"); i != -1 {
+		r := DurationRange{Max: math.MaxInt64}
+
+		var err error
+		r.Min, err = time.ParseDuration(strings.TrimSpace(s[:i]))
+		if max := strings.TrimSpace(s[i+1:]); err == nil && max != "" {
+			r.Max, err = time.ParseDuration(max)
+		}
+
+		if err != nil || r.Min > r.Max {
+			return DurationRange{}, fmt.Errorf("invalid duration range '%s'", s)
+		}
+		return r, nil
+	}
+
+	return DurationRange{}, fmt.Errorf("invalid duration range '%s'", s)
+}
+
+// ParseDurationRanges parses comma separated duration ranges e.g. "0s-100ms,2s-"
+func ParseDurationRanges(s string) ([]DurationRange, error) {
+	var ranges []DurationRange
+	for _, part := range strings.Split(s, ",") {
+		r, err := ParseDurationRange(part)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func (r DurationRange) Contains(value time.Duration) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+// parseTolerance parses an absolute "10" or relative "2%" tolerance of the value
+func parseTolerance(s string, value float64) (float64, error) {
+	if strings.HasSuffix(s, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		if err != nil || percent < 0 {
+			return 0, fmt.Errorf("invalid tolerance '%s'", s)
+		}
+		return math.Abs(value) * percent / 100, nil
+	}
+
+	tolerance, err := strconv.ParseFloat(s, 64)
+	if err != nil || tolerance < 0 {
+		return 0, fmt.Errorf("invalid tolerance '%s'", s)
+	}
+	return tolerance, nil
+}
+
+func inIntRanges(value int, ranges []IntRange) bool {
+	for _, r := range ranges {
+		if r.Contains(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func inDurationRanges(value time.Duration, ranges []DurationRange) bool {
+	for _, r := range ranges {
+		if r.Contains(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package gocdp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseIntRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected IntRange
+	}{
+		{"404", IntRange{404, 404}},
+		{" 404 ", IntRange{404, 404}},
+		{"500-599", IntRange{500, 599}},
+		{"500 - 599", IntRange{500, 599}},
+		{"500-", IntRange{500, math.MaxInt}},
+		{"0-0", IntRange{0, 0}},
+		{"-1", IntRange{StatusUnknown, StatusUnknown}},
+		{"4242~10", IntRange{4232, 4252}},
+		{"4242~2%", IntRange{4157, 4327}},
+		{"4242~0", IntRange{4242, 4242}},
+		{"100~0.5", IntRange{99, 101}},
+	}
+
+	for _, test := range tests {
+		r, err := ParseIntRange(test.input)
+		if err != nil {
+			t.Errorf("%q: %v", test.input, err)
+			continue
+		}
+		if r != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.input, test.expected, r)
+		}
+	}
+}
+
+func TestParseIntRangeInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"-",
+		"5-3",
+		"~",
+		"5~",
+		"~5",
+		"100~-5%",
+		"100~-5",
+		"100~x%",
+		"abc",
+		"1-2-3",
+		"99999999999999999999",
+		"9223372036854775807~1",
+		"1~1e300",
+		"1~1e400%",
+	}
+
+	for _, input := range inputs {
+		if r, err := ParseIntRange(input); err == nil {
+			t.Errorf("%q: expected an error, got %v", input, r)
+		}
+	}
+}
+
+func TestParseIntRanges(t *testing.T) {
+	ranges, err := ParseIntRanges("200-299,301, 4242~10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []IntRange{{200, 299}, {301, 301}, {4232, 4252}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("expected %v, got %v", expected, ranges)
+	}
+
+	if _, err := ParseIntRanges("200,,300"); err == nil {
+		t.Error("expected an error for an empty range")
+	}
+}
+
+func TestIntRangeString(t *testing.T) {
+	for _, input := range []string{"404", "500-599", "500-"} {
+		r, err := ParseIntRange(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.String() != input {
+			t.Errorf("expected %q, got %q", input, r.String())
+		}
+	}
+}
+
+func TestParseDurationRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected DurationRange
+	}{
+		{"1s", DurationRange{time.Second, time.Second}},
+		{"100ms-2s", DurationRange{100 * time.Millisecond, 2 * time.Second}},
+		{"2s-", DurationRange{2 * time.Second, math.MaxInt64}},
+		{"1s~100ms", DurationRange{900 * time.Millisecond, 1100 * time.Millisecond}},
+		{"1s~10%", DurationRange{900 * time.Millisecond, 1100 * time.Millisecond}},
+	}
+
+	for _, test := range tests {
+		r, err := ParseDurationRange(test.input)
+		if err != nil {
+			t.Errorf("%q: %v", test.input, err)
+			continue
+		}
+		if r != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.input, test.expected, r)
+		}
+	}
+}
+
+func TestParseDurationRangeInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"-",
+		"2s-1s",
+		"~",
+		"1s~",
+		"1s~-100ms",
+		"1s~-5%",
+		"1",
+		"1s-2",
+		"2540400h~2540400h",
+		"1s~1e300%",
+	}
+
+	for _, input := range inputs {
+		if r, err := ParseDurationRange(input); err == nil {
+			t.Errorf("%q: expected an error, got %v", input, r)
+		}
+	}
+}
+
+func TestIntRangeContains(t *testing.T) {
+	r := IntRange{Min: 200, Max: 299}
+	for value, expected := range map[int]bool{199: false, 200: true, 250: true, 299: true, 300: false} {
+		if r.Contains(value) != expected {
+			t.Errorf("%d: expected %v", value, expected)
+		}
+	}
+}
+
+func TestRangeFilters(t *testing.T) {
+	results := CDResults{
+		{Url: "http://t/a", Status: 200, ContentLength: 10, Words: 5, Lines: 1, Duration: 50 * time.Millisecond},
+		{Url: "http://t/b", Status: 200, ContentLength: 4242, Words: 120, Lines: 30, Duration: 900 * time.Millisecond},
+		{Url: "http://t/c", Status: 404, ContentLength: math.MaxInt, Words: math.MaxInt, Lines: math.MaxInt, Duration: 3 * time.Second},
+	}
+
+	mustIntRange := func(s string) IntRange {
+		r, err := ParseIntRange(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	mustDurationRange := func(s string) DurationRange {
+		r, err := ParseDurationRange(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		opt      TrimOption
+		expected []string
+	}{
+		{"status", WithFilterStatusRange(mustIntRange("400-499")), []string{"http://t/a", "http://t/b"}},
+		{"open length", WithFilterLengthRange(mustIntRange("4000-")), []string{"http://t/a"}},
+		{"length tolerance", WithFilterLengthRange(mustIntRange("4242~2%")), []string{"http://t/a", "http://t/c"}},
+		{"words", WithFilterWordsRange(mustIntRange("100-200")), []string{"http://t/a", "http://t/c"}},
+		{"open words", WithFilterWordsRange(mustIntRange("100-")), []string{"http://t/a"}},
+		{"lines", WithFilterLinesRange(mustIntRange("0-1"), mustIntRange("30")), []string{"http://t/c"}},
+		{"open lines", WithFilterLinesRange(mustIntRange("2-")), []string{"http://t/a"}},
+		{"duration", WithFilterDurationRange(mustDurationRange("800ms-1s")), []string{"http://t/a", "http://t/c"}},
+		{"open duration", WithFilterDurationRange(mustDurationRange("1s-")), []string{"http://t/a", "http://t/b"}},
+		{"duration tolerance", WithFilterDurationRange(mustDurationRange("50ms~10%")), []string{"http://t/b", "http://t/c"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if urls := trimURLs(t, results, test.opt); !reflect.DeepEqual(urls, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, urls)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package gocdp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// RecordFormat is the format of the raw record of a result
+type RecordFormat int
+
+const (
+	// RecordNone is the format of results without a record e.g. the results of parsers which cannot transform
+	RecordNone RecordFormat = iota
+	// RecordJSON is a JSON object e.g. a result of ffuf JSON output or a feroxbuster JSON line
+	RecordJSON
+	// RecordText is one or more lines of text e.g. a gobuster line, or a dirb line along with its location line
+	RecordText
+	// RecordCSV is a CSV row
+	RecordCSV
+	// RecordXML is an XML element
+	RecordXML
+)
+
+func (f RecordFormat) String() string {
+	switch f {
+	case RecordJSON:
+		return "json"
+	case RecordText:
+		return "text"
+	case RecordCSV:
+		return "csv"
+	case RecordXML:
+		return "xml"
+	}
+	return "none"
+}
+
+// Record is the raw record a result was parsed from, as it is in the output
+type Record struct {
+	raw      []byte
+	format   RecordFormat
+	position int
+}
+
+func newRecord(raw []byte, format RecordFormat, position int) Record {
+	return Record{
+		raw:      raw,
+		format:   format,
+		position: position,
+	}
+}
+
+func newTextRecord(text string, position int) Record {
+	return newRecord([]byte(text), RecordText, position)
+}
+
+// Raw returns a copy of the raw bytes of the record
+func (r Record) Raw() []byte {
+	return append([]byte(nil), r.raw...)
+}
+
+func (r Record) String() string {
+	return string(r.raw)
+}
+
+func (r Record) Format() RecordFormat {
+	return r.format
+}
+
+// Position is the index of the record among the records of the output, starting from 0
+func (r Record) Position() int {
+	return r.position
+}
+
+// checkRecords returns an error if any of the records is not in the format
+func checkRecords(records []Record, format RecordFormat) error {
+	for _, record := range records {
+		if record.format != format {
+			return fmt.Errorf("cannot transform the %s record at position %d as %s", record.format, record.position, format)
+		}
+	}
+	return nil
+}
+
+// jsonRecords returns the records as raw JSON, or an error if any of them is not JSON
+func jsonRecords(records []Record) ([]json.RawMessage, error) {
+	err := checkRecords(records, RecordJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var raws []json.RawMessage
+	for _, record := range records {
+		raws = append(raws, json.RawMessage(record.raw))
+	}
+	return raws, nil
+}
+
+// textRecords returns the records as text, or an error if any of them is not text
+func textRecords(records []Record) ([]string, error) {
+	err := checkRecords(records, RecordText)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, record := range records {
+		lines = append(lines, record.String())
+	}
+	return lines, nil
+}
+
+// writeTextRecords writes each text record on its own line
+func writeTextRecords(records []Record) (string, error) {
+	lines, err := textRecords(records)
+	if err != nil {
+		return "", err
+	}
+
+	writer := bytes.NewBuffer(nil)
+	for _, line := range lines {
+		writer.WriteString(fmt.Sprintln(line))
+	}
+	return writer.String(), nil
+}
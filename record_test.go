@@ -0,0 +1,79 @@
+package gocdp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecordRawIsCopy(t *testing.T) {
+	record := newRecord([]byte(`{"a":1}`), RecordJSON, 0)
+
+	raw := record.Raw()
+	raw[0] = 'x'
+	if record.String() != `{"a":1}` {
+		t.Errorf("the record was changed through Raw: %s", record.String())
+	}
+}
+
+func TestTransformMismatchedRecords(t *testing.T) {
+	input, err := RenderGobuster(CDResults{{Url: "http://t/a", Status: 200}}, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	csv, err := RenderDirSearchCSV(CDResults{{Url: "http://t/a", Status: 200}}, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := SmartParse(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		parser Parser
+		input  string
+	}{
+		{FfufParser{}, "{}"},
+		{HttpxParser{}, ""},
+		{GobusterParser{}, input},
+	}
+
+	records := []Record{results[0].Source(), newRecord([]byte(`{"url":"http://t/b"}`), RecordJSON, 1)}
+	for _, test := range tests {
+		_, err := test.parser.Transform(test.input, records)
+		if err == nil {
+			t.Errorf("%T: expected an error for the mismatched records", test.parser)
+		}
+	}
+}
+
+func TestSourceRoundTrip(t *testing.T) {
+	input, err := RenderGobuster(CDResults{{Url: "http://t/a", Status: 200}, {Url: "http://t/b", Status: 404}}, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := GobusterParser{}.Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if format := results[1].Source().Format(); format != RecordText {
+		t.Errorf("expected a text record, got %s", format)
+	}
+	if position := results[1].Source().Position(); position != 1 {
+		t.Errorf("expected position 1, got %d", position)
+	}
+	if raw := results[1].Raw(); raw != "http://t/b (Status: 404) [Size: 0]" {
+		t.Errorf("unexpected raw record %q", raw)
+	}
+
+	output, err := GobusterParser{}.Transform(input, []Record{results[1].Source()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output, "http://t/a") || !strings.Contains(output, "http://t/b") {
+		t.Errorf("unexpected transform output %q", output)
+	}
+}
@@ -0,0 +1,342 @@
+package gocdp
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocarina/gocsv"
+)
+
+// ScanInfo is the scan metadata written along with the rendered results
+type ScanInfo struct {
+	// CommandLine defaults to "gocdp convert", or "gocdp merge" followed by the Sources. The parsers rely on it
+	// to detect some formats so it is never empty
+	CommandLine string
+	// Time defaults to now
+	Time time.Time
+	// Sources are the files the results were read from, if more than one scan was combined
+	Sources []string
+}
+
+func (info ScanInfo) commandLine() string {
+	if info.CommandLine != "" {
+		return info.CommandLine
+	}
+
+	if len(info.Sources) != 0 {
+		return "gocdp merge " + strings.Join(info.Sources, " ")
+	}
+	return "gocdp convert"
+}
+
+func (info ScanInfo) scanTime() time.Time {
+	if info.Time.IsZero() {
+		return time.Now()
+	}
+	return info.Time
+}
+
+// RenderFunc renders the results in the native format of a tool. Unknown statuses and lengths are written as 0
+type RenderFunc func(CDResults, ScanInfo) (string, error)
+
+var renderFuncs = map[string]RenderFunc{
+	"ffuf-json":        RenderFfufJSON,
+	"feroxbuster-json": RenderFeroxbusterJSON,
+	"dirsearch-json":   RenderDirSearchJSON,
+	"dirsearch-csv":    RenderDirSearchCSV,
+	"dirsearch-xml":    RenderDirSearchXML,
+	"dirsearch-md":     RenderDirSearchMD,
+	"dirsearch-plain":  RenderDirSearchPlain,
+	"gobuster":         RenderGobuster,
+	"dirb":             RenderDirb,
+}
+
+// RenderNames returns the names of the formats accepted by ParseRender
+func RenderNames() []string {
+	var names []string
+	for name := range renderFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseRender returns the render function by the name of its format, see RenderNames
+func ParseRender(name string) (RenderFunc, error) {
+	render, ok := renderFuncs[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format '%s', expected one of: %s", name, strings.Join(RenderNames(), ", "))
+	}
+	return render, nil
+}
+
+// known returns 0 for the unknown values e.g. StatusUnknown, as the tools have no notion of them
+func known(value int) int {
+	if value < 0 {
+		return 0
+	}
+	return value
+}
+
+// RenderFfufJSON renders the results as ffuf JSON output
+func RenderFfufJSON(results CDResults, info ScanInfo) (string, error) {
+	type input struct {
+		FUZZ string `json:"FUZZ"`
+	}
+	type result struct {
+		Input       input  `json:"input"`
+		Position    int    `json:"position"`
+		Status      int    `json:"status"`
+		Length      int    `json:"length"`
+		Words       int    `json:"words"`
+		Lines       int    `json:"lines"`
+		ContentType string `json:"content-type"`
+		Redirect    string `json:"redirectlocation"`
+		ResultFile  string `json:"resultfile"`
+		URL         string `json:"url"`
+		Duration    int64  `json:"duration"`
+		Host        string `json:"host"`
+	}
+
+	output := struct {
+		CommandLine string     `json:"commandline"`
+		Time        string     `json:"time"`
+		Results     []result   `json:"results"`
+		Config      ffufConfig `json:"config"`
+	}{
+		CommandLine: info.commandLine(),
+		Time:        info.scanTime().Format(time.RFC3339),
+		Results:     make([]result, 0, len(results)),
+	}
+
+	for i, r := range results {
+		var host string
+		var fuzz string
+		if u, err := url.Parse(r.Url); err == nil {
+			host = u.Host
+			fuzz = path.Base(u.Path)
+			if fuzz == "/" || fuzz == "." {
+				fuzz = ""
+			}
+		}
+
+		output.Results = append(output.Results, result{
+			Input:       input{FUZZ: fuzz},
+			Position:    i + 1,
+			Status:      known(r.Status),
+			Length:      known(r.ContentLength),
+			Words:       known(r.Words),
+			Lines:       known(r.Lines),
+			ContentType: r.ContentType,
+			Redirect:    r.Redirect,
+			ResultFile:  r.ResponseFile,
+			URL:         r.Url,
+			Duration:    int64(r.Duration),
+			Host:        host,
+		})
+	}
+
+	bytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// RenderFeroxbusterJSON renders the results as feroxbuster JSON lines
+func RenderFeroxbusterJSON(results CDResults, info ScanInfo) (string, error) {
+	type result struct {
+		Type          string            `json:"type"`
+		URL           string            `json:"url"`
+		OriginalURL   string            `json:"original_url"`
+		Path          string            `json:"path"`
+		Wildcard      bool              `json:"wildcard"`
+		Status        int               `json:"status"`
+		Method        string            `json:"method"`
+		ContentLength int               `json:"content_length"`
+		Lines         int               `json:"line_count"`
+		Words         int               `json:"word_count"`
+		Headers       map[string]string `json:"headers"`
+	}
+
+	writer := bytes.NewBuffer(nil)
+	for _, r := range results {
+		var p string
+		if u, err := url.Parse(r.Url); err == nil {
+			p = u.Path
+		}
+
+		headers := make(map[string]string)
+		if r.ContentType != "" {
+			headers["content-type"] = r.ContentType
+		}
+		if r.Redirect != "" {
+			headers["location"] = r.Redirect
+		}
+
+		bytes, err := json.Marshal(result{
+			Type:          "response",
+			URL:           r.Url,
+			OriginalURL:   r.Url,
+			Path:          p,
+			Status:        known(r.Status),
+			Method:        "GET",
+			ContentLength: known(r.ContentLength),
+			Lines:         known(r.Lines),
+			Words:         known(r.Words),
+			Headers:       headers,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		writer.WriteString(fmt.Sprintln(string(bytes)))
+	}
+
+	return writer.String(), nil
+}
+
+func dirSearchResults(results CDResults) []dirSearchResult {
+	rows := make([]dirSearchResult, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, dirSearchResult{
+			URL:           r.Url,
+			Status:        known(r.Status),
+			ContentLength: known(r.ContentLength),
+			ContentType:   r.ContentType,
+			Redirect:      r.Redirect,
+		})
+	}
+	return rows
+}
+
+// RenderDirSearchJSON renders the results as dirsearch JSON output
+func RenderDirSearchJSON(results CDResults, info ScanInfo) (string, error) {
+	var output dirSearchJSONOutput
+	output.Info.Args = info.commandLine()
+	output.Info.Time = info.scanTime().Format(time.RFC3339)
+	output.Results = dirSearchResults(results)
+
+	bytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// RenderDirSearchCSV renders the results as dirsearch CSV output
+func RenderDirSearchCSV(results CDResults, info ScanInfo) (string, error) {
+	rows := dirSearchResults(results)
+	if len(rows) == 0 {
+		return "URL,Status,Size,Content Type,Redirection\n", nil
+	}
+	return gocsv.MarshalString(&rows)
+}
+
+// RenderDirSearchXML renders the results as dirsearch XML output
+func RenderDirSearchXML(results CDResults, info ScanInfo) (string, error) {
+	type dirsearchscan dirSearchXMLOutput
+
+	output := dirsearchscan{
+		Args:    info.commandLine(),
+		Time:    info.scanTime().Format(time.RFC3339),
+		Results: dirSearchResults(results),
+	}
+
+	bytes, err := xml.MarshalIndent(output, "", "\t")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s\n%s\n", `<?xml version="1.0" ?>`, string(bytes)), nil
+}
+
+// RenderDirSearchMD renders the results as a dirsearch markdown table
+func RenderDirSearchMD(results CDResults, info ScanInfo) (string, error) {
+	writer := bytes.NewBuffer(nil)
+	writer.WriteString(fmt.Sprintf("### Info\nArgs: %s\nTime: %s\n\n", info.commandLine(), info.scanTime().Format(time.RFC3339)))
+	writer.WriteString("URL | Status | Size | Content Type | Redirection\n")
+	writer.WriteString("----|--------|------|--------------|------------\n")
+
+	for _, r := range dirSearchResults(results) {
+		writer.WriteString(fmt.Sprintf("%s | %d | %d | %s | %s\n", r.URL, r.Status, r.ContentLength, r.ContentType, r.Redirect))
+	}
+	return writer.String(), nil
+}
+
+// RenderDirSearchPlain renders the results as dirsearch plain text output
+func RenderDirSearchPlain(results CDResults, info ScanInfo) (string, error) {
+	writer := bytes.NewBuffer(nil)
+	writer.WriteString(fmt.Sprintf("# Dirsearch started %s as: %s\n\n", info.scanTime().Format(time.ANSIC), info.commandLine()))
+
+	for _, r := range results {
+		line := fmt.Sprintf("%d %6dB   %s", known(r.Status), known(r.ContentLength), r.Url)
+		if r.Redirect != "" {
+			line += "    -> REDIRECTS TO: " + r.Redirect
+		}
+		writer.WriteString(fmt.Sprintln(line))
+	}
+	return writer.String(), nil
+}
+
+// RenderGobuster renders the results as gobuster output with expanded URLs
+func RenderGobuster(results CDResults, info ScanInfo) (string, error) {
+	writer := bytes.NewBuffer(nil)
+	for _, r := range results {
+		line := fmt.Sprintf("%s (Status: %d) [Size: %d]", r.Url, known(r.Status), known(r.ContentLength))
+		if r.Redirect != "" {
+			line += fmt.Sprintf(" [--> %s]", r.Redirect)
+		}
+		writer.WriteString(fmt.Sprintln(line))
+	}
+	return writer.String(), nil
+}
+
+// RenderDirb renders the results as a dirb report
+func RenderDirb(results CDResults, info ScanInfo) (string, error) {
+	// The results are grouped by the base URL they were found under, in order of appearance
+	var bases []string
+	grouped := make(map[string]CDResults)
+	for _, r := range results {
+		base := r.Url
+		if u, err := url.Parse(r.Url); err == nil {
+			base = fmt.Sprintf("%s://%s/", u.Scheme, u.Host)
+		}
+
+		if _, ok := grouped[base]; !ok {
+			bases = append(bases, base)
+		}
+		grouped[base] = append(grouped[base], r)
+	}
+
+	start := info.scanTime().Format(time.ANSIC)
+
+	writer := bytes.NewBuffer(nil)
+	writer.WriteString("\n-----------------\nDIRB v2.22    \nBy The Dark Raver\n-----------------\n\n")
+	writer.WriteString(fmt.Sprintf("START_TIME: %s\n", start))
+	// There is only room for one base, so none is written rather than the first of many
+	if len(bases) == 1 {
+		writer.WriteString(fmt.Sprintf("URL_BASE: %s\n", bases[0]))
+	}
+	writer.WriteString(fmt.Sprintf("WORDLIST_FILES: %s\n\n-----------------\n", info.commandLine()))
+
+	for _, base := range bases {
+		writer.WriteString(fmt.Sprintf("\n---- Scanning URL: %s ----\n", base))
+		for _, r := range grouped[base] {
+			writer.WriteString(fmt.Sprintf("+ %s (CODE:%d|SIZE:%d)\n", r.Url, known(r.Status), known(r.ContentLength)))
+			if r.Redirect != "" {
+				writer.WriteString(fmt.Sprintf(" (Location: '%s')\n", r.Redirect))
+			}
+		}
+	}
+
+	writer.WriteString(fmt.Sprintf("\n-----------------\nEND_TIME: %s\nDOWNLOADED: %d - FOUND: %d\n", start, len(results), len(results)))
+	return writer.String(), nil
+}
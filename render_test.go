@@ -0,0 +1,106 @@
+package gocdp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var renderResults = CDResults{
+	{Url: "http://app.example.com/", Status: 200, ContentLength: 1234, ContentType: "text/html; charset=utf-8"},
+	{Url: "http://app.example.com/admin", Status: 301, ContentLength: 0, ContentType: "text/html", Redirect: "http://app.example.com/admin/"},
+	{Url: "http://app.example.com/api/users", Status: 403, ContentLength: 42, ContentType: "application/json"},
+	{Url: "http://other.example.com/login", Status: 500, ContentLength: 7},
+}
+
+// renderFields are the fields which survive a round trip through every format
+type renderFields struct {
+	Url           string
+	Status        int
+	ContentLength int
+	Redirect      string
+	ContentType   string
+}
+
+func TestRenderRoundTrip(t *testing.T) {
+	withContentType := map[string]bool{
+		"ffuf-json":        true,
+		"feroxbuster-json": true,
+		"dirsearch-json":   true,
+		"dirsearch-csv":    true,
+		"dirsearch-xml":    true,
+		"dirsearch-md":     true,
+	}
+
+	info := ScanInfo{CommandLine: "gocdp test", Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	for _, name := range RenderNames() {
+		render, err := ParseRender(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, err := render(renderResults, info)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		parsed, err := SmartParse(strings.NewReader(output))
+		if err != nil {
+			t.Errorf("%s: could not parse the rendered output: %v\n%s", name, err, output)
+			continue
+		}
+
+		var expected []renderFields
+		for _, r := range renderResults {
+			fields := renderFields{Url: r.Url, Status: r.Status, ContentLength: r.ContentLength, Redirect: r.Redirect}
+			if withContentType[name] {
+				fields.ContentType = r.ContentType
+			}
+			expected = append(expected, fields)
+		}
+
+		var actual []renderFields
+		for _, r := range parsed {
+			fields := renderFields{Url: r.Url, Status: r.Status, ContentLength: r.ContentLength, Redirect: r.Redirect}
+			if withContentType[name] {
+				fields.ContentType = r.ContentType
+			}
+			actual = append(actual, fields)
+		}
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected\n%+v\ngot\n%+v\nfrom\n%s", name, expected, actual, output)
+		}
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	for _, name := range RenderNames() {
+		render, _ := ParseRender(name)
+		if _, err := render(nil, ScanInfo{}); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestRenderUnknownValues(t *testing.T) {
+	output, err := RenderGobuster(CDResults{{Url: "http://t/a", Status: StatusUnknown, ContentLength: LengthUnknown}}, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "http://t/a (Status: 0) [Size: 0]\n"; output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
+
+func TestParseRender(t *testing.T) {
+	if _, err := ParseRender("FFUF-JSON"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseRender("nope"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
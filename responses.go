@@ -0,0 +1,153 @@
+package gocdp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errKeptResponses = errors.New("the stored responses cannot be removed or moved when the original files are kept, as they still use them")
+
+type responseAction int
+
+const (
+	keepResponses responseAction = iota
+	removeResponses
+	moveResponses
+)
+
+// WithRemoveResponses deletes the stored response files of the removed results e.g. from ffuf with -od.
+// The files are only deleted once the trimmed file is written, see TrimReport.ResponseFiles for a dry run.
+// Cannot be used along with WithBackup, WithOutputDir or WithOutputSuffix, as the kept original files still use the responses
+func WithRemoveResponses() TrimOption {
+	return func(o *TrimOptions) {
+		o.responses = removeResponses
+	}
+}
+
+// WithMoveResponses moves the stored response files of the removed results to the directory instead of deleting them.
+// The responses keep their path relative to the trimmed file, and nothing is moved if any destination already exists.
+// The same as WithRemoveResponses, it cannot be used when the original files are kept
+func WithMoveResponses(dir string) TrimOption {
+	return func(o *TrimOptions) {
+		o.responses = moveResponses
+		o.responsesDir = dir
+	}
+}
+
+// responseFile returns the path to the stored response of the result. Relative paths which do not exist
+// are looked up from the directory of the trimmed file, as the tool may have been run from there
+func responseFile(file string, result CDResult) string {
+	response := result.ResponseFile
+	if response == "" || file == "" || filepath.IsAbs(response) {
+		return response
+	}
+
+	if _, err := os.Stat(response); err == nil {
+		return response
+	}
+
+	candidate := filepath.Join(filepath.Dir(file), response)
+	if _, err := os.Stat(candidate); err == nil {
+		return candidate
+	}
+	return response
+}
+
+// withoutKeptResponses drops the response files which are still used by a kept result
+func withoutKeptResponses(files []string, kept CDResults, file string) []string {
+	if len(files) == 0 {
+		return files
+	}
+
+	used := make(map[string]bool)
+	for _, result := range kept {
+		if result.ResponseFile != "" {
+			used[responseFile(file, result)] = true
+		}
+	}
+
+	var responses []string
+	for _, response := range files {
+		if !used[response] {
+			responses = append(responses, response)
+		}
+	}
+	return responses
+}
+
+// cleanupResponses removes or moves the stored response files of the removed results in the report
+func (o *TrimOptions) cleanupResponses(report *TrimReport) error {
+	switch o.responses {
+	case removeResponses:
+		for _, file := range report.ResponseFiles {
+			err := os.Remove(file)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	case moveResponses:
+		// Every destination is checked first so no response is moved over another
+		moves := make(map[string]string)
+		for _, file := range report.ResponseFiles {
+			dst := o.movedResponse(report.File, file)
+			if other, ok := moves[dst]; ok {
+				return fmt.Errorf("cannot move both '%s' and '%s' to '%s'", other, file, dst)
+			}
+			if _, err := os.Lstat(dst); err == nil {
+				return fmt.Errorf("cannot move '%s' to '%s', which already exists", file, dst)
+			}
+			moves[dst] = file
+		}
+
+		for _, file := range report.ResponseFiles {
+			dst := o.movedResponse(report.File, file)
+			err := os.MkdirAll(filepath.Dir(dst), 0755)
+			if err != nil {
+				return err
+			}
+
+			err = moveFile(file, dst)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// movedResponse returns where the stored response is moved to. Responses under the directory of the trimmed file
+// keep their relative path e.g. out/example.com/1a2b from meg, so responses with the same name do not collide
+func (o *TrimOptions) movedResponse(file string, response string) string {
+	if file != "" {
+		dir, dirErr := filepath.Abs(filepath.Dir(file))
+		abs, absErr := filepath.Abs(response)
+		if dirErr == nil && absErr == nil {
+			rel, err := filepath.Rel(dir, abs)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return filepath.Join(o.responsesDir, rel)
+			}
+		}
+	}
+	return filepath.Join(o.responsesDir, filepath.Base(response))
+}
+
+// moveFile renames the file, falling back to copying it when the destination is on another device
+func moveFile(src string, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+
+	if _, statErr := os.Stat(src); statErr != nil {
+		return statErr
+	}
+
+	err = copyFileAtomic(src, dst)
+	if err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
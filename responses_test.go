@@ -0,0 +1,167 @@
+package gocdp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFfufResponses writes an ffuf JSON file with a stored response for each URL, returning the response files
+func writeFfufResponses(t *testing.T, file string, urls ...string) []string {
+	t.Helper()
+
+	var results CDResults
+	var responses []string
+	for i, u := range urls {
+		response := filepath.Join(filepath.Dir(file), "responses", string(rune('a'+i)))
+		responses = append(responses, response)
+		results = append(results, CDResult{Url: u, Status: 200, ResponseFile: response})
+	}
+
+	err := os.MkdirAll(filepath.Join(filepath.Dir(file), "responses"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, response := range responses {
+		err = os.WriteFile(response, []byte("HTTP/1.1 200 OK\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output, err := RenderFfufJSON(results, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(file, []byte(output), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return responses
+}
+
+func exists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
+
+func TestSmartTrimFileRemoveResponses(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "ffuf.json")
+	responses := writeFfufResponses(t, file, "http://t/a", "http://t/b")
+
+	err := SmartTrimFile(file, []TrimOption{WithFilterURL("/b$"), WithRemoveResponses()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exists(responses[0]) {
+		t.Error("the response of the kept result was removed")
+	}
+	if exists(responses[1]) {
+		t.Error("the response of the removed result was kept")
+	}
+}
+
+func TestSmartTrimFileMoveResponses(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "ffuf.json")
+	responses := writeFfufResponses(t, file, "http://t/a", "http://t/b")
+
+	moved := filepath.Join(dir, "moved")
+	err := SmartTrimFile(file, []TrimOption{WithFilterURL("/b$"), WithMoveResponses(moved)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exists(responses[1]) || !exists(filepath.Join(moved, "responses", "b")) {
+		t.Error("the response of the removed result was not moved with its relative path")
+	}
+}
+
+func TestSmartTrimFileMoveResponsesSameName(t *testing.T) {
+	dir := writeMegDir(t)
+	index := filepath.Join(dir, "index")
+	moved := filepath.Join(t.TempDir(), "moved")
+
+	// Both responses have the same name on different hosts
+	second := filepath.Join(dir, "other.example.com", "aaa111")
+	if err := os.MkdirAll(filepath.Dir(second), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("< HTTP/1.1 404 Not Found\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(index, []byte(megIndexInput+"out/other.example.com/aaa111 https://other.example.com/ (404 Not Found)\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = SmartTrimFile(index, []TrimOption{WithFilterStatus(200, 404), WithMoveResponses(moved)}, MegParser{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, host := range []string{"web.example.com", "other.example.com"} {
+		if !exists(filepath.Join(moved, host, "aaa111")) {
+			t.Errorf("the response from %s was not moved", host)
+		}
+	}
+}
+
+func TestSmartTrimFileMoveResponsesExisting(t *testing.T) {
+	dir := t.TempDir()
+	moved := filepath.Join(dir, "moved")
+
+	var files []string
+	var responses [][]string
+	for _, name := range []string{"x", "y"} {
+		file := filepath.Join(dir, name, "ffuf.json")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+		responses = append(responses, writeFfufResponses(t, file, "http://t/a", "http://t/b"))
+	}
+
+	opts := []TrimOption{WithFilterURL("/b$"), WithMoveResponses(moved)}
+	if err := SmartTrimFile(files[0], opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second file's response has the same relative path, so it is not moved over the first
+	err := SmartTrimFile(files[1], opts)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected the existing response to be reported, got %v", err)
+	}
+	if !exists(responses[1][1]) {
+		t.Error("the response was moved over the existing one")
+	}
+}
+
+func TestSmartTrimFileResponsesKeptWithOriginal(t *testing.T) {
+	opts := map[string]TrimOption{
+		"backup":     WithBackup(""),
+		"output dir": WithOutputDir("out"),
+		"suffix":     WithOutputSuffix(".trimmed"),
+	}
+
+	for name, opt := range opts {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "ffuf.json")
+		responses := writeFfufResponses(t, file, "http://t/a", "http://t/b")
+
+		err := SmartTrimFile(file, []TrimOption{WithFilterURL("/b$"), WithRemoveResponses(), opt})
+		if err != errKeptResponses {
+			t.Errorf("%s: expected errKeptResponses, got %v", name, err)
+		}
+
+		for _, response := range responses {
+			if !exists(response) {
+				t.Errorf("%s: %s was removed", name, response)
+			}
+		}
+	}
+}
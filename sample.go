@@ -0,0 +1,186 @@
+package gocdp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/url"
+	"strings"
+)
+
+// Sampler picks which n results of a group are kept, e.g. by WithMaxResults. It returns the indexes of the picked results in the group
+type Sampler func(group CDResults, n int) []int
+
+// SampleFirst keeps the first n results in file order
+func SampleFirst(group CDResults, n int) []int {
+	var picked []int
+	for i := 0; i < n && i < len(group); i++ {
+		picked = append(picked, i)
+	}
+	return picked
+}
+
+// SampleRandom keeps n random results. The same seed always keeps the same results, use e.g. time.Now().UnixNano()
+// for a different sample on each run. Each group is shuffled by the seed combined with a hash of its URLs,
+// so groups of the same size do not keep the same positions
+func SampleRandom(seed int64) Sampler {
+	return func(group CDResults, n int) []int {
+		if n >= len(group) {
+			return SampleFirst(group, n)
+		}
+
+		h := fnv.New64a()
+		for _, result := range group {
+			h.Write([]byte(result.Url))
+			h.Write([]byte{0})
+		}
+		return rand.New(rand.NewSource(seed ^ int64(h.Sum64()))).Perm(len(group))[:n]
+	}
+}
+
+// SampleDiverse keeps the results with the most distinct content lengths, content types and path depths,
+// preferring the results spread furthest apart in file order when they are equally distinct
+func SampleDiverse(group CDResults, n int) []int {
+	if n >= len(group) {
+		return SampleFirst(group, n)
+	}
+
+	features := make([][]string, len(group))
+	for i, result := range group {
+		features[i] = []string{
+			fmt.Sprintf("length=%d", result.ContentLength),
+			fmt.Sprintf("words=%d|lines=%d", result.Words, result.Lines),
+			"type=" + KeyContentType(result),
+			fmt.Sprintf("depth=%d", pathDepth(result.Url)),
+		}
+	}
+
+	seen := make(map[string]bool)
+	isPicked := make([]bool, len(group))
+	var picked []int
+	for len(picked) < n {
+		best := -1
+		bestScore := -1
+		bestDistance := -1
+		for i := range group {
+			if isPicked[i] {
+				continue
+			}
+
+			score := 0
+			for _, feature := range features[i] {
+				if !seen[feature] {
+					score += 1
+				}
+			}
+
+			// The distance to the closest picked result, the first pick is the first result
+			distance := 0
+			for _, p := range picked {
+				d := i - p
+				if d < 0 {
+					d = -d
+				}
+				if distance == 0 || d < distance {
+					distance = d
+				}
+			}
+
+			if score > bestScore || (score == bestScore && distance > bestDistance) {
+				best, bestScore, bestDistance = i, score, distance
+			}
+		}
+
+		isPicked[best] = true
+		picked = append(picked, best)
+		for _, feature := range features[best] {
+			seen[feature] = true
+		}
+	}
+	return picked
+}
+
+// pathDepth returns the number of segments in the path of the URL
+func pathDepth(u string) int {
+	path := urlWithoutQuery(u)
+	if parsed, err := url.Parse(u); err == nil {
+		path = parsed.Path
+	}
+
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return 0
+	}
+	return strings.Count(path, "/") + 1
+}
+
+// SamplerNames returns the names of the samplers accepted by ParseSampler
+func SamplerNames() []string {
+	return []string{"diverse", "first", "random"}
+}
+
+// ParseSampler returns the sampler by its name, see SamplerNames. The seed is only used by the random sampler
+func ParseSampler(name string, seed int64) (Sampler, error) {
+	switch strings.ToLower(name) {
+	case "first":
+		return SampleFirst, nil
+	case "random":
+		return SampleRandom(seed), nil
+	case "diverse":
+		return SampleDiverse, nil
+	}
+	return nil, fmt.Errorf("unknown sampler '%s', expected one of: %s", name, strings.Join(SamplerNames(), ", "))
+}
+
+// WithSampler sets how the results kept by WithMaxResults and WithAutoCalibration are picked. Defaults to SampleFirst
+func WithSampler(sampler Sampler) TrimOption {
+	return func(o *TrimOptions) {
+		o.sampler = sampler
+	}
+}
+
+// sampleGroup returns which of the results at the indexes are kept when only n of them are, keyed by index
+func sampleGroup(sampler Sampler, results CDResults, indexes []int, n int) map[int]bool {
+	keep := make(map[int]bool)
+	if len(indexes) <= n {
+		for _, i := range indexes {
+			keep[i] = true
+		}
+		return keep
+	}
+
+	group := make(CDResults, len(indexes))
+	for j, i := range indexes {
+		group[j] = results[i]
+	}
+
+	for _, j := range sampler(group, n) {
+		keep[indexes[j]] = true
+	}
+	return keep
+}
+
+// capResults marks the results of each group, except for the n sampled ones, as removed by max.
+// Results already removed are ignored
+func (o *TrimOptions) capResults(results CDResults, removed []string) {
+	if o.maxResults <= 0 {
+		return
+	}
+
+	groups := make(map[string][]int)
+	for i, result := range results {
+		if removed[i] == "" {
+			key := o.maxKey(result)
+			groups[key] = append(groups[key], i)
+		}
+	}
+
+	for _, indexes := range groups {
+		keep := sampleGroup(o.sampler, results, indexes, o.maxResults)
+		for _, i := range indexes {
+			if !keep[i] {
+				removed[i] = "max"
+			}
+		}
+	}
+}
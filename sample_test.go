@@ -0,0 +1,132 @@
+package gocdp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func sampleGroupOf(n int, result func(i int) CDResult) CDResults {
+	var group CDResults
+	for i := 0; i < n; i++ {
+		group = append(group, result(i))
+	}
+	return group
+}
+
+func TestSampleFirst(t *testing.T) {
+	group := sampleGroupOf(5, func(i int) CDResult {
+		return CDResult{Url: fmt.Sprintf("http://t/%d", i)}
+	})
+
+	if picked := SampleFirst(group, 3); !reflect.DeepEqual(picked, []int{0, 1, 2}) {
+		t.Errorf("expected the first 3, got %v", picked)
+	}
+	if picked := SampleFirst(group, 10); len(picked) != 5 {
+		t.Errorf("expected the whole group, got %v", picked)
+	}
+}
+
+func TestSampleRandom(t *testing.T) {
+	group := sampleGroupOf(100, func(i int) CDResult {
+		return CDResult{Url: fmt.Sprintf("http://t/%d", i)}
+	})
+
+	first := SampleRandom(42)(group, 10)
+	second := SampleRandom(42)(group, 10)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("the same seed picked %v and %v", first, second)
+	}
+
+	if other := SampleRandom(43)(group, 10); reflect.DeepEqual(first, other) {
+		t.Errorf("different seeds picked the same results %v", first)
+	}
+
+	seen := make(map[int]bool)
+	for _, i := range first {
+		if i < 0 || i >= len(group) || seen[i] {
+			t.Fatalf("invalid pick %d in %v", i, first)
+		}
+		seen[i] = true
+	}
+}
+
+func TestSampleRandomGroupsOfTheSameSize(t *testing.T) {
+	var results CDResults
+	for i := 0; i < 20; i++ {
+		results = append(results, CDResult{Url: fmt.Sprintf("http://a.example.com/%d", i), Status: 200, ContentLength: 10})
+		results = append(results, CDResult{Url: fmt.Sprintf("http://b.example.com/%d", i), Status: 200, ContentLength: 10})
+	}
+
+	opts := []TrimOption{WithMaxResultsBy(3, KeyHost), WithSampler(SampleRandom(42))}
+	urls := trimURLs(t, results, opts...)
+	if len(urls) != 6 {
+		t.Fatalf("expected 3 results per host, got %v", urls)
+	}
+
+	// The kept paths differ between the hosts, and the same seed keeps the same results
+	paths := make(map[string][]string)
+	for _, u := range urls {
+		host := KeyHost(CDResult{Url: u})
+		paths[host] = append(paths[host], urlPath(u))
+	}
+	if reflect.DeepEqual(paths["a.example.com"], paths["b.example.com"]) {
+		t.Errorf("both hosts kept the same positions %v", paths["a.example.com"])
+	}
+
+	if again := trimURLs(t, results, opts...); !reflect.DeepEqual(urls, again) {
+		t.Errorf("the same seed kept %v and %v", urls, again)
+	}
+}
+
+func TestSampleDiverse(t *testing.T) {
+	// Mostly the same length, with two distinct lengths at the end
+	group := sampleGroupOf(10, func(i int) CDResult {
+		length := 100
+		if i >= 8 {
+			length = 200 + i
+		}
+		return CDResult{Url: fmt.Sprintf("http://t/%d", i), ContentLength: length}
+	})
+
+	picked := SampleDiverse(group, 3)
+	sort.Ints(picked)
+	if !reflect.DeepEqual(picked, []int{0, 8, 9}) {
+		t.Errorf("expected the distinct lengths to be picked, got %v", picked)
+	}
+}
+
+func TestPathDepth(t *testing.T) {
+	tests := map[string]int{
+		"http://t":             0,
+		"http://t/":            0,
+		"http://t/a":           1,
+		"http://t/a/b/":        2,
+		"http://t/a/b?c=/d/e":  2,
+		"http://t/a/b/c.php#x": 3,
+	}
+
+	for u, expected := range tests {
+		if depth := pathDepth(u); depth != expected {
+			t.Errorf("%s: expected %d, got %d", u, expected, depth)
+		}
+	}
+}
+
+func TestCapResults(t *testing.T) {
+	results := sampleGroupOf(6, func(i int) CDResult {
+		return CDResult{Url: fmt.Sprintf("http://t/%d", i), Status: 200 + (i%2)*204}
+	})
+
+	removed := make([]string, len(results))
+	removed[0] = "url"
+
+	options := newTrimOptions([]TrimOption{WithMaxResults(1)})
+	options.capResults(results, removed)
+
+	expected := []string{"url", "", "", "max", "max", "max"}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("expected %v, got %v", expected, removed)
+	}
+}
@@ -0,0 +1,71 @@
+package gocdp
+
+import (
+	"io"
+	"os"
+)
+
+// SplitFile splits the results of the file by the key, transforming the file once for each group so each output is in the
+// original format. The outputs are keyed by the group key
+func (cdp *CDP) SplitFile(file string, key KeyFunc, parsers ...Parser) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return cdp.Split(f, key, parsers...)
+}
+
+// Split splits the results by the key, transforming the input once for each group so each output is in the
+// original format. The outputs are keyed by the group key
+func (cdp *CDP) Split(reader io.Reader, key KeyFunc, parsers ...Parser) (map[string]string, error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parsers) == 0 {
+		parsers = cdp.defaultParsers
+	}
+
+	input := string(bytes)
+	var parser Parser
+	for _, p := range parsers {
+		if p.CanTransform() && p.CanParse(input) {
+			parser = p
+			break
+		}
+	}
+
+	if parser == nil {
+		return nil, errNoParser
+	}
+
+	results, err := parser.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]string)
+	for k, group := range results.GroupBy(key) {
+		var records []Record
+		for _, result := range group {
+			records = append(records, result.source)
+		}
+
+		outputs[k], err = parser.Transform(input, records)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return outputs, nil
+}
+
+func SplitFile(file string, key KeyFunc, parsers ...Parser) (map[string]string, error) {
+	return c.SplitFile(file, key, parsers...)
+}
+
+func Split(reader io.Reader, key KeyFunc, parsers ...Parser) (map[string]string, error) {
+	return c.Split(reader, key, parsers...)
+}
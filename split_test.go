@@ -0,0 +1,43 @@
+package gocdp
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "ffuf.json")
+	writeFfufFile(t, file, "http://a.example.com/x", "http://b.example.com/y", "http://a.example.com/z")
+
+	outputs, err := SplitFile(file, KeyHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"a.example.com": {"http://a.example.com/x", "http://a.example.com/z"},
+		"b.example.com": {"http://b.example.com/y"},
+	}
+
+	if len(outputs) != len(expected) {
+		t.Fatalf("expected %d groups, got %d", len(expected), len(outputs))
+	}
+
+	for host, urls := range expected {
+		results, err := SmartParse(strings.NewReader(outputs[host]), FfufParser{})
+		if err != nil {
+			t.Fatalf("%s: %v", host, err)
+		}
+
+		var actual []string
+		for _, result := range results {
+			actual = append(actual, result.Url)
+		}
+		if !reflect.DeepEqual(actual, urls) {
+			t.Errorf("%s: expected %v, got %v", host, urls, actual)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package gocdp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// trimWrite is a trimmed file waiting to be committed
+type trimWrite struct {
+	file string
+	tmp  string
+	// rollback is a copy of the file before it was overwritten, if it existed
+	rollback  string
+	committed bool
+}
+
+var errSkipVerifyAllOrNothing = errors.New("the output is always verified when trimming all or nothing, it cannot be used with WithSkipVerify")
+
+// WithAllOrNothing trims all the files before writing any of them. Every output is verified, see WithVerify,
+// and written to a temporary file first. The files are only replaced once all of them are ready, and the replaced
+// files are restored if any of them fails. Only applies to SmartTrimFiles. Using it with WithSkipVerify is an error
+func WithAllOrNothing() TrimOption {
+	return func(o *TrimOptions) {
+		o.allOrNothing = true
+	}
+}
+
+func (cdp *CDP) smartTrimFilesAllOrNothing(files []string, opts []TrimOption, parsers ...Parser) error {
+	options := newTrimOptions(opts)
+
+	var reports []*TrimReport
+	var infos []os.FileInfo
+	for _, file := range files {
+		report, info, err := cdp.smartTrimFile(file, options, parsers...)
+		if err != nil {
+			if err == errNoParser {
+				if cdp.failNoParserErr {
+					return fmt.Errorf("no trimmer found for file '%s'", file)
+				}
+				continue
+			}
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		reports = append(reports, report)
+		infos = append(infos, info)
+	}
+
+	for _, report := range reports {
+		if options.report != nil {
+			options.report(report)
+		}
+	}
+
+	if options.dryRun {
+		return nil
+	}
+
+	err := commitAllOrNothing(reports, infos, options)
+	if err != nil {
+		return err
+	}
+
+	// The stored responses are only cleaned up once every file is written, as they cannot be restored
+	for _, report := range reports {
+		err = options.cleanupResponses(report)
+		if err != nil {
+			return fmt.Errorf("%s: %w", report.File, err)
+		}
+	}
+	return nil
+}
+
+// rename is replaced in tests to fail part way through a commit
+var rename = os.Rename
+
+// commitAllOrNothing writes the trimmed files, restoring the written files if any of them fails
+func commitAllOrNothing(reports []*TrimReport, infos []os.FileInfo, options *TrimOptions) (err error) {
+	var writes []*trimWrite
+	var backups []string
+	defer func() {
+		for _, write := range writes {
+			if err != nil {
+				write.restore()
+			}
+			write.cleanup()
+		}
+
+		if err != nil {
+			for _, backup := range backups {
+				os.Remove(backup)
+			}
+		}
+	}()
+
+	for i, report := range reports {
+		tmp, err := writeTempFile(report.OutputFile, []byte(report.Output), infos[i])
+		if err != nil {
+			return fmt.Errorf("%s: %w", report.File, err)
+		}
+
+		write := &trimWrite{
+			file: report.OutputFile,
+			tmp:  tmp,
+		}
+		writes = append(writes, write)
+
+		if _, err := os.Stat(report.OutputFile); err == nil {
+			write.rollback, err = writeRollback(report.OutputFile)
+			if err != nil {
+				return fmt.Errorf("%s: %w", report.File, err)
+			}
+		}
+	}
+
+	if options.backupSuffix != "" {
+		for _, report := range reports {
+			if report.OutputFile != report.File {
+				continue
+			}
+
+			backup := report.File + options.backupSuffix
+			created, err := backupFile(report.File, backup)
+			if err != nil {
+				return fmt.Errorf("%s: %w", report.File, err)
+			}
+			if created {
+				backups = append(backups, backup)
+			}
+		}
+	}
+
+	for _, write := range writes {
+		err = rename(write.tmp, write.file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", write.file, err)
+		}
+		write.committed = true
+	}
+
+	return nil
+}
+
+// writeRollback keeps a copy of the file, which is restored if the trim fails. A hard link is used when possible
+func writeRollback(file string) (string, error) {
+	rollback := filepath.Join(filepath.Dir(file), fmt.Sprintf(".%s.%d.rollback", filepath.Base(file), os.Getpid()))
+	os.Remove(rollback)
+
+	if err := os.Link(file, rollback); err == nil {
+		return rollback, nil
+	}
+
+	err := copyFileAtomic(file, rollback)
+	if err != nil {
+		return "", err
+	}
+	return rollback, nil
+}
+
+// restore puts back the file as it was before it was committed
+func (w *trimWrite) restore() {
+	if !w.committed {
+		return
+	}
+
+	if w.rollback != "" {
+		os.Rename(w.rollback, w.file)
+	} else {
+		os.Remove(w.file)
+	}
+}
+
+func (w *trimWrite) cleanup() {
+	os.Remove(w.tmp)
+	if w.rollback != "" {
+		os.Remove(w.rollback)
+	}
+}
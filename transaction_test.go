@@ -0,0 +1,139 @@
+package gocdp
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSmartTrimFilesAllOrNothingRestoresOnRenameFailure(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "f1.json")
+	second := filepath.Join(dir, "f2.json")
+	writeFfufFile(t, first, "http://t/a", "http://t/b")
+	writeFfufFile(t, second, "http://t/a", "http://t/b")
+
+	renames := 0
+	rename = func(oldpath string, newpath string) error {
+		renames++
+		if renames == 2 {
+			return errors.New("rename failed")
+		}
+		return os.Rename(oldpath, newpath)
+	}
+	defer func() {
+		rename = os.Rename
+	}()
+
+	err := SmartTrimFiles([]string{first, second}, []TrimOption{WithFilterURL("/b$"), WithAllOrNothing(), WithBackup("")})
+	if err == nil {
+		t.Fatal("expected the failed rename to be returned")
+	}
+
+	expected := []string{"http://t/a", "http://t/b"}
+	for _, file := range []string{first, second} {
+		if urls := fileURLs(t, file); !reflect.DeepEqual(urls, expected) {
+			t.Errorf("%s has %v, expected it to be restored to %v", file, urls, expected)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); name != "f1.json" && name != "f2.json" {
+			t.Errorf("%s was left behind", name)
+		}
+	}
+}
+
+func TestSmartTrimFilesAllOrNothingKeepsExistingBackup(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b", "http://t/c")
+
+	for _, pattern := range []string{"/b$", "/c$"} {
+		err := SmartTrimFiles([]string{file}, []TrimOption{WithFilterURL(pattern), WithAllOrNothing(), WithBackup("")})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"http://t/a", "http://t/b", "http://t/c"}
+	if urls := fileURLs(t, file+".orig"); !reflect.DeepEqual(urls, expected) {
+		t.Errorf("backup has %v, expected the original %v", urls, expected)
+	}
+}
+
+func TestSmartTrimFilesAllOrNothingWritesNothingOnTrimFailure(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "f1.json")
+	second := filepath.Join(dir, "f2.txt")
+	writeFfufFile(t, first, "http://t/a", "http://t/b")
+	if err := os.WriteFile(second, []byte("not a scan\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cdp := New(FailNoParserErrs())
+	opts := []TrimOption{WithFilterURL("/b$"), WithAllOrNothing()}
+	if err := cdp.SmartTrimFiles([]string{first, second}, opts); err == nil {
+		t.Fatal("expected the file without a trimmer to fail")
+	}
+
+	if urls := fileURLs(t, first); !reflect.DeepEqual(urls, []string{"http://t/a", "http://t/b"}) {
+		t.Errorf("%s was written before every file was trimmed: %v", first, urls)
+	}
+
+	// Without FailNoParserErrs the file is skipped and the rest are written
+	if err := New().SmartTrimFiles([]string{first, second}, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if urls := fileURLs(t, first); !reflect.DeepEqual(urls, []string{"http://t/a"}) {
+		t.Errorf("expected %s to be trimmed, got %v", first, urls)
+	}
+}
+
+func TestSmartTrimFilesAllOrNothingDryRun(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b")
+
+	var reports []*TrimReport
+	opts := []TrimOption{WithFilterURL("/b$"), WithAllOrNothing(), WithDryRun(), WithReport(func(r *TrimReport) {
+		reports = append(reports, r)
+	})}
+	if err := SmartTrimFiles([]string{file}, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) != 1 || reports[0].Kept != 1 || reports[0].Total != 2 {
+		t.Errorf("unexpected reports %+v", reports)
+	}
+	if urls := fileURLs(t, file); !reflect.DeepEqual(urls, []string{"http://t/a", "http://t/b"}) {
+		t.Errorf("expected the dry run to leave %s as is, got %v", file, urls)
+	}
+}
+
+func TestAllOrNothingRejectsSkipVerify(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b")
+
+	for _, opts := range [][]TrimOption{
+		{WithSkipVerify(), WithAllOrNothing()},
+		{WithAllOrNothing(), WithSkipVerify()},
+	} {
+		err := SmartTrimFiles([]string{file}, append(opts, WithFilterURL("/b$")))
+		if err != errSkipVerifyAllOrNothing {
+			t.Errorf("expected %v, got %v", errSkipVerifyAllOrNothing, err)
+		}
+	}
+
+	if urls := fileURLs(t, file); !reflect.DeepEqual(urls, []string{"http://t/a", "http://t/b"}) {
+		t.Errorf("expected %s to be left as is, got %v", file, urls)
+	}
+}
@@ -0,0 +1,77 @@
+package gocdp
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+)
+
+// URLListParser parses plain lists of URLs, one per line, such as the output of gau or waybackurls.
+// Since almost any text file could look like a list of URLs, it is not one of the default parsers
+// and must be explicitly used e.g. with the AdditionalParsers option.
+// The status and content length of the results are StatusUnknown and LengthUnknown
+type URLListParser struct {
+}
+
+func (URLListParser) isURL(line string) bool {
+	u, err := url.Parse(line)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (p URLListParser) Parse(input string) (CDResults, error) {
+	var results CDResults
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !p.isURL(line) {
+			continue
+		}
+
+		results = append(results, CDResult{
+			Url:           line,
+			Status:        StatusUnknown,
+			ContentLength: LengthUnknown,
+			source:        newTextRecord(scanner.Text(), len(results)),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (p URLListParser) CanParse(input string) bool {
+	found := false
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if !p.isURL(line) {
+			return false
+		}
+		found = true
+	}
+
+	return found && scanner.Err() == nil
+}
+
+func (URLListParser) CanTransform() bool {
+	return true
+}
+
+func (URLListParser) Transform(input string, records []Record) (string, error) {
+	return writeTextRecords(records)
+}
@@ -0,0 +1,82 @@
+package gocdp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const urlListInput = `https://example.com/a
+https://example.com/b?x=1
+
+  http://example.com/c
+`
+
+func TestURLListParser(t *testing.T) {
+	parser := URLListParser{}
+	if !parser.CanParse(urlListInput) {
+		t.Fatal("expected the URL list to be parseable")
+	}
+
+	results, err := parser.Parse(urlListInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"https://example.com/a|-1|-1||",
+		"https://example.com/b?x=1|-1|-1||",
+		"http://example.com/c|-1|-1||",
+	}
+	if fields := resultFields(results); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+
+	for _, result := range results {
+		if result.IsProbed() {
+			t.Errorf("%s should not be probed", result.Url)
+		}
+	}
+}
+
+func TestURLListParserCanParse(t *testing.T) {
+	inputs := []string{
+		"",
+		"\n\n",
+		"https://example.com/a\nnot a url\n",
+		"ftp://example.com/a\n",
+		"/relative/path\n",
+	}
+
+	for _, input := range inputs {
+		if (URLListParser{}).CanParse(input) {
+			t.Errorf("%q should not be parsed as a URL list", input)
+		}
+	}
+}
+
+func TestURLListParserIsNotDefault(t *testing.T) {
+	if _, err := SmartParse(strings.NewReader(urlListInput)); err != errNoParser {
+		t.Errorf("expected no default parser for a URL list, got %v", err)
+	}
+
+	results, err := New(AdditionalParsers(URLListParser{})).SmartParse(strings.NewReader(urlListInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Errorf("expected 3 results, got %d", len(results))
+	}
+}
+
+func TestURLListParserTransform(t *testing.T) {
+	cdp := New(AdditionalParsers(URLListParser{}))
+	output, err := cdp.SmartTrim(strings.NewReader(urlListInput), []TrimOption{WithFilterURL(`\?`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "https://example.com/a\n  http://example.com/c\n"; output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+}
@@ -0,0 +1,36 @@
+package gocdp
+
+import (
+	"net"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// buildURL builds a URL from its parts, leaving out the port when it is the default for the scheme
+func buildURL(scheme string, host string, port string, path string) string {
+	if strings.Contains(host, ":") && !strings.HasPrefix(host, "[") {
+		host = "[" + host + "]"
+	}
+
+	if port != "" && defaultPorts[scheme] != port {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+
+	return scheme + "://" + host + path
+}
+
+// urlWithoutQuery returns the URL without the query string and fragment
+func urlWithoutQuery(u string) string {
+	if i := strings.IndexAny(u, "?#"); i != -1 {
+		return u[:i]
+	}
+	return u
+}
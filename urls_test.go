@@ -0,0 +1,26 @@
+package gocdp
+
+import "testing"
+
+func TestBuildURL(t *testing.T) {
+	tests := []struct {
+		scheme   string
+		host     string
+		port     string
+		path     string
+		expected string
+	}{
+		{"http", "example.com", "80", "/admin", "http://example.com/admin"},
+		{"https", "example.com", "443", "admin", "https://example.com/admin"},
+		{"https", "example.com", "8443", "", "https://example.com:8443/"},
+		{"http", "example.com", "", "/", "http://example.com/"},
+		{"http", "::1", "8080", "/x", "http://[::1]:8080/x"},
+		{"http", "[::1]", "80", "/x", "http://[::1]/x"},
+	}
+
+	for _, test := range tests {
+		if actual := buildURL(test.scheme, test.host, test.port, test.path); actual != test.expected {
+			t.Errorf("buildURL(%q, %q, %q, %q): expected %q, got %q", test.scheme, test.host, test.port, test.path, test.expected, actual)
+		}
+	}
+}
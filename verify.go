@@ -0,0 +1,98 @@
+package gocdp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VerifyError is returned by a trim with WithVerify when the transformed output is not parseable
+// or does not contain exactly the kept results
+type VerifyError struct {
+	Expected int
+	Actual   int
+
+	// Missing are the kept results which are not in the output and Unexpected are the results
+	// in the output which were not kept
+	Missing    CDResults
+	Unexpected CDResults
+
+	// Err is the error from parsing the output, if any
+	Err error
+}
+
+func (e *VerifyError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("verify failed: could not parse the trimmed output: %v", e.Err)
+	}
+
+	var details []string
+	if len(e.Missing) != 0 {
+		details = append(details, fmt.Sprintf("%d missing e.g. %s", len(e.Missing), e.Missing[0].Url))
+	}
+	if len(e.Unexpected) != 0 {
+		details = append(details, fmt.Sprintf("%d unexpected e.g. %s", len(e.Unexpected), e.Unexpected[0].Url))
+	}
+
+	return fmt.Sprintf("verify failed: expected %d results, found %d (%s)", e.Expected, e.Actual, strings.Join(details, ", "))
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// verifyKey is what is compared between the kept results and the results parsed from the output
+func verifyKey(result CDResult) string {
+	return fmt.Sprintf("%s|%d|%d|%s", result.Url, result.Status, result.ContentLength, result.Redirect)
+}
+
+// verifyTransform parses the output with the parser and checks it contains exactly the kept results
+func verifyTransform(parser Parser, output string, kept CDResults) error {
+	if len(kept) != 0 && !parser.CanParse(output) {
+		return &VerifyError{
+			Expected: len(kept),
+			Err:      errNoParser,
+		}
+	}
+
+	parsed, err := parser.Parse(output)
+	if err != nil {
+		return &VerifyError{
+			Expected: len(kept),
+			Err:      err,
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, result := range kept {
+		counts[verifyKey(result)] += 1
+	}
+
+	var unexpected CDResults
+	for _, result := range parsed {
+		key := verifyKey(result)
+		if counts[key] == 0 {
+			unexpected = append(unexpected, result)
+			continue
+		}
+		counts[key] -= 1
+	}
+
+	var missing CDResults
+	for _, result := range kept {
+		key := verifyKey(result)
+		if counts[key] > 0 {
+			counts[key] -= 1
+			missing = append(missing, result)
+		}
+	}
+
+	if len(missing) != 0 || len(unexpected) != 0 {
+		return &VerifyError{
+			Expected:   len(kept),
+			Actual:     len(parsed),
+			Missing:    missing,
+			Unexpected: unexpected,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package gocdp
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// droppingParser is an ffuf parser whose transform loses every kept result but the first
+type droppingParser struct {
+	FfufParser
+}
+
+func (p droppingParser) Transform(input string, records []Record) (string, error) {
+	if len(records) > 1 {
+		records = records[:1]
+	}
+	return p.FfufParser.Transform(input, records)
+}
+
+func TestSmartTrimFileVerifiesByDefault(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b", "http://t/c")
+
+	err := SmartTrimFile(file, []TrimOption{WithFilterURL("/c$")}, droppingParser{})
+
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("expected a *VerifyError, got %v", err)
+	}
+	if verifyErr.Expected != 2 || verifyErr.Actual != 1 || len(verifyErr.Missing) != 1 {
+		t.Errorf("unexpected verify error %+v", verifyErr)
+	}
+
+	expected := []string{"http://t/a", "http://t/b", "http://t/c"}
+	if urls := fileURLs(t, file); !reflect.DeepEqual(urls, expected) {
+		t.Errorf("file has %v, expected it to be untouched", urls)
+	}
+}
+
+func TestSmartTrimFileSkipVerify(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f1.json")
+	writeFfufFile(t, file, "http://t/a", "http://t/b", "http://t/c")
+
+	err := SmartTrimFile(file, []TrimOption{WithFilterURL("/c$"), WithSkipVerify()}, droppingParser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if urls := fileURLs(t, file); !reflect.DeepEqual(urls, []string{"http://t/a"}) {
+		t.Errorf("file has %v", urls)
+	}
+}
+
+func TestSmartTrimReportsVerified(t *testing.T) {
+	var results CDResults
+	for _, u := range []string{"http://t/a", "http://t/b"} {
+		results = append(results, CDResult{Url: u, Status: 200})
+	}
+	input, err := RenderFfufJSON(results, ScanInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report *TrimReport
+	_, err = SmartTrim(strings.NewReader(input), []TrimOption{WithFilterURL("/b$"), WithReport(func(r *TrimReport) {
+		report = r
+	})})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Verified || report.Kept != 1 {
+		t.Errorf("expected 1 verified result, got %+v", report)
+	}
+}